@@ -39,6 +39,24 @@ import (
 	"github.com/chaos-mesh/chaos-mesh/pkg/chaosdaemon/tproxyconfig"
 )
 
+// splitCombinedActions expands a rule that sets both Delay and Replace into two
+// consecutive rules carrying the delay and the replacement respectively. The
+// proxy evaluates rules in order, so the target is delayed first and its
+// contents are replaced afterwards.
+func splitCombinedActions(rule v1alpha1.PodHttpChaosBaseRule) []v1alpha1.PodHttpChaosBaseRule {
+	if rule.Actions.Delay == nil || rule.Actions.Replace == nil {
+		return []v1alpha1.PodHttpChaosBaseRule{rule}
+	}
+
+	delayRule := rule
+	delayRule.Actions = v1alpha1.PodHttpChaosActions{Delay: rule.Actions.Delay}
+
+	replaceRule := rule
+	replaceRule.Actions.Delay = nil
+
+	return []v1alpha1.PodHttpChaosBaseRule{delayRule, replaceRule}
+}
+
 // Reconciler applys podhttpchaos
 type Reconciler struct {
 	client.Client
@@ -137,7 +155,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	for _, rule := range obj.Spec.Rules {
 		proxyPortsMap[uint32(rule.Port)] = true
-		rules = append(rules, rule.PodHttpChaosBaseRule)
+		rules = append(rules, splitCombinedActions(rule.PodHttpChaosBaseRule)...)
 	}
 
 	var proxyPorts []uint32
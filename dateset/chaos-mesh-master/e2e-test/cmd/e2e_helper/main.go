@@ -82,6 +82,7 @@ func newServer(dataDir string) *server {
 	s.mux.HandleFunc("/dns", s.dnsTest)
 	s.mux.HandleFunc("/stress", s.stressCondition)
 	s.mux.HandleFunc("/http", s.httpEcho)
+	s.mux.HandleFunc("/http-other", s.httpEcho)
 	s.mux.HandleFunc("/setup_https", s.SetupHTTPSServer)
 	return s
 }
@@ -312,6 +312,16 @@ var _ = ginkgo.Describe("[Basic]", func() {
 			ginkgo.It("[Pause]", func() {
 				httpchaostestcases.TestcaseHttpReplacePauseAndUnPause(ns, cli, client, port)
 			})
+			ginkgo.It("[PathMatch]", func() {
+				httpchaostestcases.TestcaseHttpReplaceMatchingPathThenRecover(ns, cli, client, port)
+			})
+		})
+
+		// http chaos case in [HTTPDelayAndReplace] context
+		ginkgo.Context("[HTTPDelayAndReplace]", func() {
+			ginkgo.It("[Schedule]", func() {
+				httpchaostestcases.TestcaseHttpDelayAndReplaceThenRecover(ns, cli, client, port)
+			})
 		})
 
 		// http chaos case in [HTTPReplaceBody] context
@@ -0,0 +1,153 @@
+// Copyright 2024 Chaos Mesh Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package httpchaos
+
+import (
+	"context"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"github.com/chaos-mesh/chaos-mesh/e2e-test/e2e/util"
+)
+
+func TestcaseHttpDelayAndReplaceThenRecover(
+	ns string,
+	cli client.Client,
+	c HTTPE2EClient,
+	port uint16,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	By("waiting on e2e helper ready")
+	err := util.WaitHTTPE2EHelperReady(*c.C, c.IP, port)
+	framework.ExpectNoError(err, "wait e2e helper ready error")
+
+	secret := "Bar"
+
+	By("waiting for assertion normal behaviour")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		resp, dur, err := getPodHttpDelayWithSecret(c, port, secret)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+
+		s := resp.Header.Get(SECRET)
+		second := dur.Seconds()
+		klog.Infof("Status(%d), Secret(%s), delay %fs", resp.StatusCode, s, second)
+
+		if s == secret && second < 1 {
+			return true, nil
+		}
+		return false, nil
+	})
+	framework.ExpectNoError(err, "helper server doesn't work as expected")
+	By("deploy helper server successfully")
+
+	By("create http delay and replace chaos CRD objects")
+	delay := "1s"
+	replaceSecret := "Foo!"
+
+	httpChaos := &v1alpha1.HTTPChaos{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "http-chaos",
+			Namespace: ns,
+		},
+		Spec: v1alpha1.HTTPChaosSpec{
+			PodSelector: v1alpha1.PodSelector{
+				Selector: v1alpha1.PodSelectorSpec{
+					GenericSelectorSpec: v1alpha1.GenericSelectorSpec{
+						Namespaces:     []string{ns},
+						LabelSelectors: map[string]string{"app": "http"},
+					},
+				},
+				Mode: v1alpha1.OneMode,
+			},
+			Port:   8080,
+			Target: "Request",
+			PodHttpChaosActions: v1alpha1.PodHttpChaosActions{
+				Delay: &delay,
+				Replace: &v1alpha1.PodHttpChaosReplaceActions{
+					Headers: map[string]string{
+						SECRET: replaceSecret,
+					},
+				},
+			},
+		},
+	}
+	err = cli.Create(ctx, httpChaos)
+	framework.ExpectNoError(err, "create http chaos error")
+
+	By("waiting for assertion HTTP delay and replace")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		resp, dur, err := getPodHttpDelayWithSecret(c, port, secret)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+
+		s := resp.Header.Get(SECRET)
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+
+		second := dur.Seconds()
+		klog.Infof("Status(%d), Secret(%s), Body(%s), delay %fs", resp.StatusCode, s, string(b), second)
+
+		// both actions must be effective on the same request:
+		// the request is delayed >= 1s and its header is replaced
+		if s == replaceSecret && second >= 1 {
+			return true, nil
+		}
+		return false, nil
+	})
+	framework.ExpectNoError(err, "http chaos doesn't work as expected")
+	By("apply http chaos successfully")
+
+	By("delete chaos CRD objects")
+	// delete chaos CRD
+	err = cli.Delete(ctx, httpChaos)
+	framework.ExpectNoError(err, "failed to delete http chaos")
+
+	By("waiting for assertion recovering")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		resp, dur, err := getPodHttpDelayWithSecret(c, port, secret)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+
+		s := resp.Header.Get(SECRET)
+		second := dur.Seconds()
+		klog.Infof("Status(%d), Secret(%s), delay %fs", resp.StatusCode, s, second)
+
+		if s == secret && second < 1 {
+			return true, nil
+		}
+		return false, nil
+	})
+	framework.ExpectNoError(err, "fail to recover http chaos")
+}
@@ -375,6 +375,130 @@ func TestcaseHttpReplacePauseAndUnPause(
 	cli.Delete(ctx, httpChaos)
 }
 
+func TestcaseHttpReplaceMatchingPathThenRecover(
+	ns string,
+	cli client.Client,
+	c HTTPE2EClient,
+	port uint16,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	By("waiting on e2e helper ready")
+	err := util.WaitHTTPE2EHelperReady(*c.C, c.IP, port)
+	framework.ExpectNoError(err, "wait e2e helper ready error")
+
+	secret := "Bar"
+
+	By("waiting for assertion normal behaviour")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		for _, path := range []string{"/http", "/http-other"} {
+			resp, err := getPodHttpWithPath(c, port, path, secret, "")
+			if err != nil {
+				return false, err
+			}
+			defer resp.Body.Close()
+
+			s := resp.Header.Get(SECRET)
+			klog.Infof("Path(%s), Status(%d), Secret(%s)", path, resp.StatusCode, s)
+
+			if s != secret {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	framework.ExpectNoError(err, "helper server doesn't work as expected")
+	By("deploy helper server successfully")
+
+	By("create http replace chaos CRD objects with a path selector")
+	replaceSecret := "Foo!"
+	chaosPath := "/http$"
+
+	httpChaos := &v1alpha1.HTTPChaos{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "http-chaos",
+			Namespace: ns,
+		},
+		Spec: v1alpha1.HTTPChaosSpec{
+			PodSelector: v1alpha1.PodSelector{
+				Selector: v1alpha1.PodSelectorSpec{
+					GenericSelectorSpec: v1alpha1.GenericSelectorSpec{
+						Namespaces:     []string{ns},
+						LabelSelectors: map[string]string{"app": "http"},
+					},
+				},
+				Mode: v1alpha1.OneMode,
+			},
+			Port:   8080,
+			Path:   &chaosPath,
+			Target: "Request",
+			PodHttpChaosActions: v1alpha1.PodHttpChaosActions{
+				Replace: &v1alpha1.PodHttpChaosReplaceActions{
+					Headers: map[string]string{
+						SECRET: replaceSecret,
+					},
+				},
+			},
+		},
+	}
+	err = cli.Create(ctx, httpChaos)
+	framework.ExpectNoError(err, "create http chaos error")
+
+	By("waiting for assertion HTTP replace on the matching path only")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		resp, err := getPodHttpWithPath(c, port, "/http", secret, "")
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+
+		s := resp.Header.Get(SECRET)
+		klog.Infof("Path(/http), Status(%d), Secret(%s)", resp.StatusCode, s)
+		if s != replaceSecret {
+			return false, nil
+		}
+
+		// the non-matching path must stay untouched
+		other, err := getPodHttpWithPath(c, port, "/http-other", secret, "")
+		if err != nil {
+			return false, err
+		}
+		defer other.Body.Close()
+
+		s = other.Header.Get(SECRET)
+		klog.Infof("Path(/http-other), Status(%d), Secret(%s)", other.StatusCode, s)
+		return s == secret, nil
+	})
+	framework.ExpectNoError(err, "http chaos doesn't work as expected")
+	By("apply http chaos successfully")
+
+	By("delete chaos CRD objects")
+	// delete chaos CRD
+	err = cli.Delete(ctx, httpChaos)
+	framework.ExpectNoError(err, "failed to delete http chaos")
+
+	By("waiting for assertion recovering")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		for _, path := range []string{"/http", "/http-other"} {
+			resp, err := getPodHttpWithPath(c, port, path, secret, "")
+			if err != nil {
+				return false, err
+			}
+			defer resp.Body.Close()
+
+			s := resp.Header.Get(SECRET)
+			klog.Infof("Path(%s), Status(%d), Secret(%s)", path, resp.StatusCode, s)
+
+			if s != secret {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	framework.ExpectNoError(err, "fail to recover http chaos")
+}
+
 func TestcaseHttpReplaceBodyThenRecover(
 	ns string,
 	cli client.Client,
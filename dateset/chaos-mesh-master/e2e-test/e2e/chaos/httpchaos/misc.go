@@ -42,6 +42,19 @@ func getPodHttp(c HTTPE2EClient, port uint16, secret, body string) (*http.Respon
 	return resp, err
 }
 
+func getPodHttpWithPath(c HTTPE2EClient, port uint16, path, secret, body string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s:%d%s", c.IP, port, path), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(SECRET, secret)
+	client := &http.Client{
+		Transport: &http.Transport{},
+	}
+	resp, err := client.Do(request)
+	return resp, err
+}
+
 func getPodHttpNoSecret(c HTTPE2EClient, port uint16) (*http.Response, error) {
 	return getPodHttp(c, port, "", "")
 }
@@ -64,3 +77,14 @@ func getPodHttpDelay(c HTTPE2EClient, port uint16) (*http.Response, time.Duratio
 
 	return resp, time.Now().Sub(start), nil
 }
+
+// get pod http delay with the given secret header
+func getPodHttpDelayWithSecret(c HTTPE2EClient, port uint16, secret string) (*http.Response, time.Duration, error) {
+	start := time.Now()
+	resp, err := getPodHttp(c, port, secret, "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return resp, time.Now().Sub(start), nil
+}
@@ -33,9 +33,11 @@ type column[T any] struct {
 }
 
 type Formatter[T any] struct {
-	options *Options
-	columns []*column[T]
-	printer func(buf *encodeState, entry *T, indent string)
+	options    *Options
+	columns    []*column[T]
+	allColumns []*column[T]
+	columnMap  map[string]*column[T]
+	printer    func(buf *encodeState, entry *T, indent string)
 }
 
 // NewFormatter returns a Formatter that will turn entries of type T into JSON representation
@@ -124,13 +126,41 @@ func NewFormatter[T any](cols columns.ColumnMap[T], options ...Option) *Formatte
 	}
 
 	tf := &Formatter[T]{
-		options: opts,
-		columns: ncols,
+		options:    opts,
+		columns:    ncols,
+		allColumns: ncols,
+		columnMap:  make(map[string]*column[T], len(ncols)),
+	}
+	for _, col := range ncols {
+		tf.columnMap[strings.ToLower(col.column.Name)] = col
 	}
 	tf.printer = tf.getPrinter(0, ncols)
 	return tf
 }
 
+// SetShowColumns takes a list of column names that will be emitted when using the output methods
+// Returns an error if any of the columns is not available. Passing nil restores the default of
+// emitting all columns.
+func (f *Formatter[T]) SetShowColumns(colNames []string) error {
+	if colNames == nil {
+		f.columns = f.allColumns
+		f.printer = f.getPrinter(0, f.columns)
+		return nil
+	}
+
+	newColumns := make([]*column[T], 0, len(colNames))
+	for _, c := range colNames {
+		col, ok := f.columnMap[strings.ToLower(c)]
+		if !ok {
+			return fmt.Errorf("column %q is invalid", strings.ToLower(c))
+		}
+		newColumns = append(newColumns, col)
+	}
+	f.columns = newColumns
+	f.printer = f.getPrinter(0, newColumns)
+	return nil
+}
+
 func (f *Formatter[T]) getPrinter(level int, cols []*column[T]) func(buf *encodeState, entry *T, indent string) {
 	levelIndent := []byte("  ")
 	colsWithParent := map[string][]*column[T]{}
@@ -168,13 +198,15 @@ func (f *Formatter[T]) getPrinter(level int, cols []*column[T]) func(buf *encode
 				}
 			})
 		} else {
-			if f.options.prettyPrint {
-				funcs = append(funcs, func(buf *encodeState, entry *T, indent string) {
+			// check prettyPrint at call time, as FormatEntriesJSONLines
+			// toggles it without rebuilding the printer
+			funcs = append(funcs, func(buf *encodeState, entry *T, indent string) {
+				if f.options.prettyPrint {
 					buf.WriteByte('\n')
 					buf.WriteString(indent)
 					buf.Write(levelIndent)
-				})
-			}
+				}
+			})
 			first = false
 		}
 	}
@@ -287,6 +319,28 @@ func (f *Formatter[T]) FormatEntries(entries []*T) string {
 	return buf.String()
 }
 
+// FormatEntriesJSONLines returns a slice of entries as JSON lines: one compact JSON object per
+// line, emitting only the columns selected via SetShowColumns. Pretty printing is ignored, as
+// each entry has to stay on a single line.
+func (f *Formatter[T]) FormatEntriesJSONLines(entries []*T) string {
+	buf := bufpool.Get().(*encodeState)
+	buf.Reset()
+	defer bufpool.Put(buf)
+
+	prettyPrint := f.options.prettyPrint
+	f.options.prettyPrint = false
+	defer func() { f.options.prettyPrint = prettyPrint }()
+
+	for l, entry := range entries {
+		f.formatEntry(buf, entry, "")
+		if l < len(entries)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String()
+}
+
 type floatEncoder int // number of bits
 
 // from encoding/json/encode.go
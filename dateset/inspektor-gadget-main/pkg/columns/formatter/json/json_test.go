@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"unsafe"
 
@@ -150,6 +151,31 @@ func TestJSONFormatter_PrettyFormatEntries(t *testing.T) {
 	assert.Equal(t, expected, formatter.FormatEntries(testEntries))
 }
 
+func TestJSONFormatter_FormatEntriesJSONLines(t *testing.T) {
+	formatter := NewFormatter(testColumns)
+	require.NoError(t, formatter.SetShowColumns([]string{"name", "age"}))
+
+	out := formatter.FormatEntriesJSONLines(testEntries[:3])
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+		assert.Len(t, decoded, 2)
+		assert.Contains(t, decoded, "name")
+		assert.Contains(t, decoded, "age")
+	}
+
+	// Unknown columns must be rejected
+	assert.Error(t, formatter.SetShowColumns([]string{"nonexistent"}))
+
+	// Resetting to nil restores all columns
+	require.NoError(t, formatter.SetShowColumns(nil))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(formatter.FormatEntry(testEntries[0])), &decoded))
+	assert.Len(t, decoded, 5)
+}
+
 func BenchmarkFormatter(b *testing.B) {
 	b.StopTimer()
 	formatter := NewFormatter(testColumns)
@@ -0,0 +1,71 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// FormatCSV returns header and entries as RFC 4180 CSV rows, using the
+// currently visible columns and their order; width, alignment and truncation
+// settings do not apply.
+func (tf *TextColumnsFormatter[T]) FormatCSV(entries []*T) string {
+	var buf strings.Builder
+	_ = tf.WriteCSV(&buf, entries)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// WriteCSV writes header and entries as RFC 4180 CSV rows to writer, using
+// the currently visible columns and their order
+func (tf *TextColumnsFormatter[T]) WriteCSV(writer io.Writer, entries []*T) error {
+	w := csv.NewWriter(writer)
+
+	header := make([]string, 0, len(tf.showColumns))
+	for _, column := range tf.showColumns {
+		name := column.col.Name
+		switch tf.options.HeaderStyle {
+		case HeaderStyleUppercase:
+			name = strings.ToUpper(name)
+		case HeaderStyleLowercase:
+			name = strings.ToLower(name)
+		}
+		header = append(header, name)
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(tf.showColumns))
+	for _, entry := range entries {
+		if entry == nil {
+			// like the text path, a nil entry still produces an empty row
+			for i := range row {
+				row[i] = ""
+			}
+		} else {
+			for i, column := range tf.showColumns {
+				row[i] = column.rawField(entry)
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+// escapeMarkdownCell escapes characters that would break a Markdown table
+// cell: pipes delimit cells and newlines delimit rows.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// FormatMarkdownTable returns header and entries as a GitHub flavored
+// Markdown table, using the currently visible columns and their order; width
+// and truncation settings do not apply, but right-aligned columns are
+// emitted with a right-aligning separator.
+func (tf *TextColumnsFormatter[T]) FormatMarkdownTable(entries []*T) string {
+	var sb strings.Builder
+
+	sb.WriteRune('|')
+	for _, column := range tf.showColumns {
+		name := column.col.Name
+		switch tf.options.HeaderStyle {
+		case HeaderStyleUppercase:
+			name = strings.ToUpper(name)
+		case HeaderStyleLowercase:
+			name = strings.ToLower(name)
+		}
+		sb.WriteString(" " + escapeMarkdownCell(name) + " |")
+	}
+
+	sb.WriteString("\n|")
+	for _, column := range tf.showColumns {
+		if column.col.Alignment == columns.AlignRight {
+			sb.WriteString(" ---: |")
+		} else {
+			sb.WriteString(" --- |")
+		}
+	}
+
+	for _, entry := range entries {
+		sb.WriteString("\n|")
+		for _, column := range tf.showColumns {
+			if entry == nil {
+				// like the text path, a nil entry still produces an empty row
+				sb.WriteString("  |")
+				continue
+			}
+			sb.WriteString(" " + escapeMarkdownCell(column.rawField(entry)) + " |")
+		}
+	}
+
+	return sb.String()
+}
@@ -14,6 +14,10 @@
 
 package textcolumns
 
+import (
+	"strings"
+)
+
 type HeaderStyle int
 
 const (
@@ -26,6 +30,7 @@ const (
 	DividerSpace = " "
 	DividerTab   = "\t"
 	DividerDash  = "—"
+	DividerBar   = "│"
 	DividerNone  = ""
 )
 
@@ -35,9 +40,14 @@ type Options struct {
 	AutoScale      bool        // if enabled, the screen size will be used to scale the widths
 	ColumnDivider  string      // defines the string that should be used as spacer in between columns (default " ")
 	DefaultColumns []string    // defines which columns to show by default; will be set to all visible columns if nil
+	GroupDivider   string      // replaces ColumnDivider between adjacent columns belonging to different column groups; disabled if empty
 	HeaderStyle    HeaderStyle // defines how column headers are decorated (e.g. uppercase/lowercase)
+	NoColor        bool        // disables all cell coloring, e.g. when the output is not a TTY
 	RowDivider     string      // defines the (to be repeated) string that should be used below the header
 	ShouldTruncate bool        // defines whether to truncate strings or not
+
+	CellStyles  map[string]func(value any) string // per-column functions returning an ANSI escape code (or "") per cell
+	WrapColumns map[string]bool                   // per-column flag to wrap overlong cells onto continuation lines instead of truncating
 }
 
 func DefaultOptions() *Options {
@@ -72,6 +82,15 @@ func WithDefaultColumns(columns []string) Option {
 	}
 }
 
+// WithGroupDivider sets the string that should be used as divider between adjacent columns that
+// belong to different column groups (see the `group:` attribute of the column tag); within a
+// group, ColumnDivider is used as usual
+func WithGroupDivider(divider string) Option {
+	return func(opts *Options) {
+		opts.GroupDivider = divider
+	}
+}
+
 // WithHeaderStyle sets the style to be used for the table header
 func WithHeaderStyle(headerStyle HeaderStyle) Option {
 	return func(opts *Options) {
@@ -86,6 +105,46 @@ func WithRowDivider(divider string) Option {
 	}
 }
 
+// WithCellStyle sets a function that gets called with the cell value for each cell of the named
+// column and returns an ANSI escape code (or an empty string to leave the cell unstyled); the code
+// wraps only the cell content and is not considered for width calculation
+func WithCellStyle(columnName string, style func(value any) string) Option {
+	return func(opts *Options) {
+		if opts.CellStyles == nil {
+			opts.CellStyles = make(map[string]func(value any) string)
+		}
+		opts.CellStyles[strings.ToLower(columnName)] = style
+	}
+}
+
+// WithColorByColumn sets a function that gets called with the cell value for each cell of the
+// named column and returns an ANSI color code (or an empty string to leave the cell uncolored);
+// the code wraps the already padded and aligned cell together with a reset, so escape sequences
+// don't skew widths. It shares the cell style mechanism with WithCellStyle.
+func WithColorByColumn(columnName string, color func(value any) string) Option {
+	return WithCellStyle(columnName, color)
+}
+
+// WithNoColor disables all cell coloring set up through WithColorByColumn or WithCellStyle,
+// e.g. when the output is not a TTY
+func WithNoColor(noColor bool) Option {
+	return func(opts *Options) {
+		opts.NoColor = noColor
+	}
+}
+
+// WithWrapColumn makes cells of the named column that exceed the column width wrap onto
+// additional lines within the row instead of being truncated; cells of the other columns are
+// left blank on continuation lines
+func WithWrapColumn(columnName string) Option {
+	return func(opts *Options) {
+		if opts.WrapColumns == nil {
+			opts.WrapColumns = make(map[string]bool)
+		}
+		opts.WrapColumns[strings.ToLower(columnName)] = true
+	}
+}
+
 // WithShouldTruncate sets whether strings should be truncated.
 func WithShouldTruncate(ellipsis bool) Option {
 	return func(opts *Options) {
@@ -24,11 +24,45 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/ellipsis"
 )
 
+// ansiReset clears any style previously applied through a CellStyles function
+const ansiReset = "\x1b[0m"
+
 func (tf *TextColumnsFormatter[T]) setFormatter(column *Column[T]) {
 	ff := columns.GetFieldAsStringExt[T](column.col, 'f', column.col.Precision)
+	style := tf.options.CellStyles[strings.ToLower(column.col.Name)]
+	if tf.options.NoColor {
+		style = nil
+	}
+	column.rawField = ff
+	column.wrap = tf.options.WrapColumns[strings.ToLower(column.col.Name)]
 	column.formatter = func(entry *T) string {
-		return tf.buildFixedString(ff(entry), column.calculatedWidth, column.col.EllipsisType, column.col.Alignment)
+		cell := tf.buildFixedString(ff(entry), column.calculatedWidth, column.col.EllipsisType, column.col.Alignment)
+		if style != nil {
+			// The escape codes wrap the already padded cell, so they don't affect the width calculation
+			if code := style(column.col.Get(entry).Interface()); code != "" {
+				return code + cell + ansiReset
+			}
+		}
+		return cell
+	}
+}
+
+// dividerBefore returns the divider to print in front of the visible column at index; GroupDivider
+// is used whenever the column starts a new column group
+func (tf *TextColumnsFormatter[T]) dividerBefore(index int) string {
+	if tf.options.GroupDivider != "" && tf.showColumns[index].col.Group != tf.showColumns[index-1].col.Group {
+		return tf.options.GroupDivider
+	}
+	return tf.options.ColumnDivider
+}
+
+// dividersWidth returns the combined width of all dividers between the visible columns
+func (tf *TextColumnsFormatter[T]) dividersWidth() int {
+	width := 0
+	for i := 1; i < len(tf.showColumns); i++ {
+		width += len([]rune(tf.dividerBefore(i)))
 	}
+	return width
 }
 
 func (tf *TextColumnsFormatter[T]) buildFixedString(s string, length int, ellipsisType ellipsis.EllipsisType, alignment columns.Alignment) string {
@@ -46,10 +80,83 @@ func (tf *TextColumnsFormatter[T]) buildFixedString(s string, length int, ellips
 	if len(shortened) == length {
 		return string(shortened)
 	}
-	if alignment == columns.AlignLeft {
+	switch alignment {
+	case columns.AlignLeft:
 		return string(shortened) + tf.fillString[0:length-len(shortened)]
+	case columns.AlignCenter:
+		// odd remainders deterministically put the extra space on the right
+		left := (length - len(shortened)) / 2
+		return tf.fillString[0:left] + string(shortened) + tf.fillString[0:length-len(shortened)-left]
+	default:
+		return tf.fillString[0:length-len(shortened)] + string(shortened)
+	}
+}
+
+// wrapCell splits s into chunks of at most length runes, each padded to length according to
+// alignment, so an overlong cell can span several lines of the same row
+func (tf *TextColumnsFormatter[T]) wrapCell(s string, length int, alignment columns.Alignment) []string {
+	if length <= 0 {
+		return []string{""}
+	}
+
+	rs := []rune(s)
+	lines := make([]string, 0, len(rs)/length+1)
+	for start := 0; ; start += length {
+		end := start + length
+		if end > len(rs) {
+			end = len(rs)
+		}
+		chunk := string(rs[start:end])
+		fill := tf.fillString[0 : length-(end-start)]
+		switch alignment {
+		case columns.AlignLeft:
+			lines = append(lines, chunk+fill)
+		case columns.AlignCenter:
+			left := len(fill) / 2
+			lines = append(lines, fill[0:left]+chunk+fill[left:])
+		default:
+			lines = append(lines, fill+chunk)
+		}
+		if end >= len(rs) {
+			break
+		}
+	}
+	return lines
+}
+
+// formatWrappedEntry formats an entry whose wrap-enabled columns may span several lines;
+// cells of the other columns are blank on continuation lines so the columns stay aligned
+func (tf *TextColumnsFormatter[T]) formatWrappedEntry(entry *T) string {
+	cells := make([][]string, len(tf.showColumns))
+	lineCount := 1
+	for i, col := range tf.showColumns {
+		if col.wrap {
+			cells[i] = tf.wrapCell(col.rawField(entry), col.calculatedWidth, col.col.Alignment)
+			if len(cells[i]) > lineCount {
+				lineCount = len(cells[i])
+			}
+			continue
+		}
+		cells[i] = []string{col.formatter(entry)}
 	}
-	return tf.fillString[0:length-len(shortened)] + string(shortened)
+
+	var row strings.Builder
+	for line := 0; line < lineCount; line++ {
+		if line > 0 {
+			row.WriteString("\n")
+		}
+		for i, col := range tf.showColumns {
+			if i > 0 {
+				row.WriteString(tf.dividerBefore(i))
+			}
+			if line < len(cells[i]) {
+				row.WriteString(cells[i][line])
+				continue
+			}
+			row.WriteString(tf.fillString[0:col.calculatedWidth])
+		}
+	}
+	return row.String()
 }
 
 // FormatEntry returns an entry as a formatted string, respecting the given formatting settings
@@ -58,10 +165,14 @@ func (tf *TextColumnsFormatter[T]) FormatEntry(entry *T) string {
 		return ""
 	}
 
+	if len(tf.options.WrapColumns) > 0 {
+		return tf.formatWrappedEntry(entry)
+	}
+
 	var row strings.Builder
 	for i, col := range tf.showColumns {
 		if i > 0 {
-			row.WriteString(tf.options.ColumnDivider)
+			row.WriteString(tf.dividerBefore(i))
 		}
 		row.WriteString(col.formatter(entry))
 	}
@@ -74,7 +185,7 @@ func (tf *TextColumnsFormatter[T]) FormatHeader() string {
 	var row strings.Builder
 	for i, column := range tf.showColumns {
 		if i > 0 {
-			row.WriteString(tf.options.ColumnDivider)
+			row.WriteString(tf.dividerBefore(i))
 		}
 		name := column.col.Name
 		switch tf.options.HeaderStyle {
@@ -97,7 +208,7 @@ func (tf *TextColumnsFormatter[T]) FormatRowDivider() string {
 	rowDividerLen := 0
 	for i, col := range tf.showColumns {
 		if i > 0 {
-			rowDividerLen += len([]rune(tf.options.ColumnDivider))
+			rowDividerLen += len([]rune(tf.dividerBefore(i)))
 		}
 		rowDividerLen += col.calculatedWidth
 	}
@@ -43,7 +43,7 @@ func (tf *TextColumnsFormatter[T]) RecalculateWidths(maxWidth int, force bool) {
 	occurrences := make(map[string]int)
 
 	// width of all dividers between the columns
-	dividerWidth := (len(tf.showColumns) - 1) * len([]rune(tf.options.ColumnDivider))
+	dividerWidth := tf.dividersWidth()
 
 	// calculate the minimum required length (that is: length of dividers plus width (in case it's fixed or MinWidth is
 	// set) or one character (if no width was specified)) - else we could get negative values on auto-scaling
@@ -300,7 +300,7 @@ func (tf *TextColumnsFormatter[T]) AdjustWidthsToContent(entries []*T, considerH
 	tf.buildFillString()
 
 	// Last but not least, add column dividers
-	totalWidth += len([]rune(tf.options.ColumnDivider)) * (len(tf.showColumns) - 1)
+	totalWidth += tf.dividersWidth()
 
 	if maxWidth == 0 || totalWidth <= maxWidth {
 		// Yay, it fits! (or user doesn't care)
@@ -27,6 +27,8 @@ type Column[T any] struct {
 	calculatedWidth int
 	treatAsFixed    bool
 	formatter       func(*T) string
+	rawField        func(*T) string
+	wrap            bool
 }
 
 type TextColumnsFormatter[T any] struct {
@@ -68,6 +68,80 @@ func TestTextColumnsFormatter_FormatEntryAndTable(t *testing.T) {
 	})
 }
 
+func TestTextColumnsFormatter_FormatCSV(t *testing.T) {
+	formatter := NewFormatter(testColumns)
+
+	assert.Equal(t,
+		strings.Join([]string{
+			"NAME,AGE,SIZE,BALANCE,CANDANCE",
+			"Alice,32,1.74,1000,true",
+			"Bob,26,1.73,-200,true",
+			"Eve,99,5.12,1000000,false",
+			",,,,",
+		}, "\n"),
+		formatter.FormatCSV(testEntries),
+	)
+
+	// CSV output follows the current column selection
+	require.NoError(t, formatter.SetShowColumns([]string{"name", "age"}))
+	assert.Equal(t,
+		strings.Join([]string{
+			"NAME,AGE",
+			"Alice,32",
+			"Bob,26",
+			"Eve,99",
+			",",
+		}, "\n"),
+		formatter.FormatCSV(testEntries),
+	)
+}
+
+func TestTextColumnsFormatter_FormatMarkdownTable(t *testing.T) {
+	formatter := NewFormatter(testColumns)
+	require.NoError(t, formatter.SetShowColumns([]string{"name", "age", "canDance"}))
+
+	entries := append([]*testStruct{}, testEntries...)
+	entries[2] = &testStruct{Name: "Eve|Mallory", Age: 99, CanDance: false}
+
+	assert.Equal(t,
+		strings.Join([]string{
+			"| NAME | AGE | CANDANCE |",
+			"| --- | ---: | --- |",
+			"| Alice | 32 | true |",
+			"| Bob | 26 | true |",
+			"| Eve\\|Mallory | 99 | false |",
+			"|  |  |  |",
+		}, "\n"),
+		formatter.FormatMarkdownTable(entries),
+	)
+}
+
+func TestTextColumnsFormatter_GroupDivider(t *testing.T) {
+	type groupedStruct struct {
+		Node string `column:"node,width:6,group:k8s"`
+		Pod  string `column:"pod,width:6,group:k8s"`
+		Comm string `column:"comm,width:6,group:runtime"`
+		Pid  uint32 `column:"pid,width:4,align:right,group:runtime"`
+	}
+	entries := []*groupedStruct{
+		{"node-1", "nginx", "curl", 42},
+	}
+
+	cols, err := columns.NewColumns[groupedStruct]()
+	require.NoError(t, err)
+
+	formatter := NewFormatter(cols.GetColumnMap(), WithAutoScale(false), WithGroupDivider(" "+DividerBar+" "))
+
+	// The group divider replaces the column divider between the k8s and runtime groups
+	assert.Equal(t, "NODE   POD    │ COMM    PID", formatter.FormatHeader())
+	assert.Equal(t, "node-1 nginx  │ curl     42", formatter.FormatEntry(entries[0]))
+
+	// The divider sticks to the group boundary when the column selection changes
+	require.NoError(t, formatter.SetShowColumns([]string{"node", "pid"}))
+	assert.Equal(t, "NODE   │  PID", formatter.FormatHeader())
+	assert.Equal(t, "node-1 │   42", formatter.FormatEntry(entries[0]))
+}
+
 func TestTextColumnsFormatter_FormatHeader(t *testing.T) {
 	formatter := NewFormatter(testColumns)
 
@@ -296,3 +370,103 @@ func TestDynamicFields(t *testing.T) {
 	assert.Equal(t, "STR              INT32            BOOL            ", formatter.FormatHeader())
 	assert.Equal(t, "foobar           1234567890       true            ", formatter.FormatEntry(&empty{}))
 }
+
+func TestTextColumnsFormatter_AlignCenter(t *testing.T) {
+	type testStruct struct {
+		Name   string `column:"name,width:6"`
+		Status string `column:"status,width:9,align:center"`
+	}
+	entries := []*testStruct{
+		{"Alice", "ok"},
+		{"Bob", "degraded"},
+	}
+	cols, err := columns.NewColumns[testStruct]()
+	require.Nil(t, err, "error initializing: %s", err)
+
+	formatter := NewFormatter(cols.GetColumnMap())
+
+	// An odd remainder puts the extra space on the right
+	assert.Equal(t, "Alice     ok    ", formatter.FormatEntry(entries[0]))
+	assert.Equal(t, "Bob    degraded ", formatter.FormatEntry(entries[1]))
+}
+
+func TestTextColumnsFormatter_Wrap(t *testing.T) {
+	type testStruct struct {
+		Name    string `column:"name,width:6"`
+		Comment string `column:"comment,width:10"`
+		Age     uint   `column:"age,width:4,align:right"`
+	}
+	entries := []*testStruct{
+		{"Alice", "a very long comment", 32},
+		{"Bob", "short", 26},
+	}
+	cols, err := columns.NewColumns[testStruct]()
+	require.Nil(t, err, "error initializing: %s", err)
+
+	formatter := NewFormatter(cols.GetColumnMap(), WithWrapColumn("comment"))
+
+	// The overlong comment wraps onto a continuation line with the other columns blank
+	wrapped := formatter.FormatEntry(entries[0])
+	assert.Equal(t,
+		"Alice  a very lon   32\n"+
+			"       g comment      ",
+		wrapped)
+
+	// 19 runes at width 10 span exactly two lines
+	assert.Len(t, strings.Split(wrapped, "\n"), 2)
+
+	// Cells that fit stay on a single line
+	assert.Equal(t, "Bob    short        26", formatter.FormatEntry(entries[1]))
+
+	// Continuation lines keep all columns aligned in the full table
+	table := formatter.FormatTable(entries)
+	assert.Equal(t,
+		strings.Join([]string{
+			"NAME   COMMENT     AGE",
+			"Alice  a very lon   32",
+			"       g comment      ",
+			"Bob    short        26",
+		}, "\n"),
+		table)
+	for _, line := range strings.Split(table, "\n") {
+		assert.Len(t, []rune(line), 22)
+	}
+}
+
+func TestTextColumnsFormatter_CellStyle(t *testing.T) {
+	const red = "\x1b[31m"
+	formatter := NewFormatter(testColumns, WithCellStyle("balance", func(value any) string {
+		if value.(int) < 0 {
+			return red
+		}
+		return ""
+	}))
+
+	// Positive balances stay unstyled
+	assert.Equal(t, "Alice        32   1.74     1000 true    ", formatter.FormatEntry(testEntries[0]))
+
+	// Negative balances get wrapped in the escape code, only around the balance cell
+	styled := formatter.FormatEntry(testEntries[1])
+	assert.Equal(t, "Bob          26   1.73 "+red+"    -200"+ansiReset+" true    ", styled)
+
+	// Stripping the escapes yields the same row as an unstyled formatter, so widths are unaffected
+	stripped := strings.ReplaceAll(strings.ReplaceAll(styled, red, ""), ansiReset, "")
+	assert.Equal(t, NewFormatter(testColumns).FormatEntry(testEntries[1]), stripped)
+}
+
+func TestTextColumnsFormatter_ColorByColumn(t *testing.T) {
+	const red = "\x1b[31m"
+	colorNegative := func(value any) string {
+		if value.(int) < 0 {
+			return red
+		}
+		return ""
+	}
+
+	formatter := NewFormatter(testColumns, WithColorByColumn("balance", colorNegative))
+	assert.Equal(t, "Bob          26   1.73 "+red+"    -200"+ansiReset+" true    ", formatter.FormatEntry(testEntries[1]))
+
+	// WithNoColor turns coloring off entirely, e.g. for non-TTY output
+	formatter = NewFormatter(testColumns, WithColorByColumn("balance", colorNegative), WithNoColor(true))
+	assert.Equal(t, "Bob          26   1.73     -200 true    ", formatter.FormatEntry(testEntries[1]))
+}
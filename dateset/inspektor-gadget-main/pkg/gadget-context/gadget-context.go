@@ -21,10 +21,14 @@ package gadgetcontext
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
@@ -42,7 +46,7 @@ import (
 // instance and communicates with gadget and runtime.
 type GadgetContext struct {
 	ctx                      context.Context
-	cancel                   context.CancelFunc
+	cancel                   context.CancelCauseFunc
 	id                       string
 	gadget                   gadgets.GadgetDesc
 	gadgetParams             *params.Params
@@ -62,11 +66,34 @@ type GadgetContext struct {
 	dataSources      map[string]datasource.DataSource
 	dataOperators    []operators.DataOperator
 	vars             map[string]any
+	exportableVars   map[string]struct{}
 	params           []*api.Param
 	prepareCallbacks []func()
 	loaded           bool
 	imageName        string
 	metadata         []byte
+	outputSinks      []OutputSink
+	metricsFactory   MetricsFactory
+	dsCounters       map[string]*atomic.Uint64
+	timeoutUpdated   chan struct{}
+}
+
+// OutputSink receives formatted entries emitted during a gadget run; register
+// several sinks (e.g. stdout, a file, a remote) to multiplex the output.
+type OutputSink interface {
+	// Name identifies the sink in log messages
+	Name() string
+	// Write receives one formatted entry; entries keep arriving even after an
+	// error, so a sink must be able to recover or keep failing gracefully
+	Write(entry []byte) error
+}
+
+// MetricsFactory creates the instruments used to observe a gadget run; it is implemented by
+// the metrics backend of the caller (e.g. prometheus). Passing one via WithMetricsFactory
+// enables per-data-source throughput counting.
+type MetricsFactory interface {
+	// NewCounter returns a function that increments the counter identified by name by one
+	NewCounter(name string) func()
 }
 
 func New(
@@ -83,7 +110,7 @@ func New(
 	timeout time.Duration,
 	gadgetInfo *runTypes.GadgetInfo,
 ) *GadgetContext {
-	gCtx, cancel := context.WithCancel(ctx)
+	gCtx, cancel := context.WithCancelCause(ctx)
 
 	return &GadgetContext{
 		ctx:                      gCtx,
@@ -111,7 +138,7 @@ func NewOCI(
 	imageName string,
 	options ...Option,
 ) *GadgetContext {
-	gCtx, cancel := context.WithCancel(ctx)
+	gCtx, cancel := context.WithCancelCause(ctx)
 	gadgetContext := &GadgetContext{
 		ctx:    gCtx,
 		cancel: cancel,
@@ -129,6 +156,51 @@ func NewOCI(
 	return gadgetContext
 }
 
+// Clone returns an independent GadgetContext bound to the given parent context,
+// carrying copies of the data sources, params, vars and metadata of c. The
+// clone starts out unloaded, so LoadGadgetInfo can be called on it again, e.g.
+// to replay the same api.GadgetInfo against a second runtime.
+func (c *GadgetContext) Clone(ctx context.Context) *GadgetContext {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	gCtx, cancel := context.WithCancelCause(ctx)
+	clone := &GadgetContext{
+		ctx:       gCtx,
+		cancel:    cancel,
+		id:        c.id,
+		args:      slices.Clone(c.args),
+		logger:    c.logger,
+		timeout:   c.timeout,
+		imageName: c.imageName,
+
+		dataSources:    make(map[string]datasource.DataSource, len(c.dataSources)),
+		vars:           maps.Clone(c.vars),
+		params:         slices.Clone(c.params),
+		metadata:       slices.Clone(c.metadata),
+		metricsFactory: c.metricsFactory,
+	}
+	for name, ds := range c.dataSources {
+		di := &api.DataSource{
+			Name:        ds.Name(),
+			Fields:      ds.Fields(),
+			Tags:        ds.Tags(),
+			Annotations: ds.Annotations(),
+		}
+		if ds.ByteOrder() == binary.BigEndian {
+			di.Flags |= api.DataSourceFlagsBigEndian
+		}
+		copied, err := datasource.NewFromAPI(di)
+		if err != nil {
+			clone.logger.Warnf("cloning data source %q: %v", name, err)
+			continue
+		}
+		clone.instrumentDataSource(copied)
+		clone.dataSources[name] = copied
+	}
+	return clone
+}
+
 func (c *GadgetContext) ID() string {
 	return c.id
 }
@@ -138,7 +210,20 @@ func (c *GadgetContext) Context() context.Context {
 }
 
 func (c *GadgetContext) Cancel() {
-	c.cancel()
+	c.cancel(nil)
+}
+
+// CancelWithReason cancels the gadget run like Cancel, but attaches err as the
+// cancelation cause, so callers waiting on the context can report why the run
+// was aborted (see CancelCause)
+func (c *GadgetContext) CancelWithReason(err error) {
+	c.cancel(err)
+}
+
+// CancelCause returns the reason passed to CancelWithReason, context.Canceled
+// after a plain Cancel, or nil while the context is still alive
+func (c *GadgetContext) CancelCause() error {
+	return context.Cause(c.ctx)
 }
 
 func (c *GadgetContext) Parser() parser.Parser {
@@ -178,9 +263,41 @@ func (c *GadgetContext) OperatorsParamCollection() params.Collection {
 }
 
 func (c *GadgetContext) Timeout() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	return c.timeout
 }
 
+// SetTimeout changes the gadget timeout after construction; a wait already in
+// progress under WaitForTimeoutOrDone picks up the new value and reschedules
+// its deadline, still measured from the start of the wait. Once the context
+// has expired, SetTimeout has no effect.
+func (c *GadgetContext) SetTimeout(d time.Duration) {
+	c.lock.Lock()
+	c.timeout = d
+	if c.timeoutUpdated == nil {
+		c.timeoutUpdated = make(chan struct{}, 1)
+	}
+	ch := c.timeoutUpdated
+	c.lock.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// TimeoutUpdated returns the channel SetTimeout signals on; WaitForTimeoutOrDone
+// uses it to reschedule a deadline that is already being awaited
+func (c *GadgetContext) TimeoutUpdated() <-chan struct{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.timeoutUpdated == nil {
+		c.timeoutUpdated = make(chan struct{}, 1)
+	}
+	return c.timeoutUpdated
+}
+
 func (c *GadgetContext) GadgetInfo() *runTypes.GadgetInfo {
 	return c.gadgetInfo
 }
@@ -197,10 +314,49 @@ func (c *GadgetContext) RegisterDataSource(t datasource.Type, name string) (data
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	ds := datasource.New(t, name)
+	c.instrumentDataSource(ds)
 	c.dataSources[name] = ds
 	return ds, nil
 }
 
+// metricsSubscriberPriority places the counting subscriber behind all operators, so only
+// records that actually get emitted to consumers are counted
+const metricsSubscriberPriority = 10000
+
+// instrumentDataSource subscribes a counter to ds when a MetricsFactory was configured; the
+// hot path only does an atomic add plus the factory's increment. Must be called with c.lock
+// held.
+func (c *GadgetContext) instrumentDataSource(ds datasource.DataSource) {
+	if c.metricsFactory == nil {
+		return
+	}
+	if c.dsCounters == nil {
+		c.dsCounters = make(map[string]*atomic.Uint64)
+	}
+	counter := &atomic.Uint64{}
+	c.dsCounters[ds.Name()] = counter
+	inc := c.metricsFactory.NewCounter(ds.Name())
+	ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		counter.Add(1)
+		if inc != nil {
+			inc()
+		}
+		return nil
+	}, metricsSubscriberPriority)
+}
+
+// DataSourceStats returns the number of records emitted per data source since the context was
+// created; it is empty unless a MetricsFactory was configured via WithMetricsFactory
+func (c *GadgetContext) DataSourceStats() map[string]uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stats := make(map[string]uint64, len(c.dsCounters))
+	for name, counter := range c.dsCounters {
+		stats[name] = counter.Load()
+	}
+	return stats
+}
+
 func (c *GadgetContext) GetDataSources() map[string]datasource.DataSource {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -216,6 +372,72 @@ func (c *GadgetContext) GetVar(varName string) (any, bool) {
 	return res, ok
 }
 
+// exportedVarPrefix marks annotations of api.GadgetInfo that carry an exportable var; the
+// remainder of the key is the var name, the value its JSON encoding
+const exportedVarPrefix = "exported-var."
+
+// RegisterExportableVar marks the variable with the given name to be serialized along with the
+// gadget info (see SerializeGadgetInfo), so it survives crossing the gRPC boundary; values must
+// be JSON-serializable and are subject to the usual JSON round-trip conversions (e.g. numbers
+// come back as float64)
+func (c *GadgetContext) RegisterExportableVar(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.exportableVars == nil {
+		c.exportableVars = make(map[string]struct{})
+	}
+	c.exportableVars[name] = struct{}{}
+}
+
+// SetTypedVar stores a variable like SetVar, but keeps the static type
+// information on the caller side; use it together with GetTypedVar to avoid
+// unchecked type assertions.
+func SetTypedVar[T any](c *GadgetContext, varName string, value T) {
+	c.SetVar(varName, value)
+}
+
+// GetTypedVar returns the variable with the given name if it exists and holds
+// a value of type T; otherwise it returns the zero value of T and false
+// instead of panicking like a failed type assertion would.
+func GetTypedVar[T any](c *GadgetContext, varName string) (T, bool) {
+	var zero T
+	res, ok := c.vars[varName]
+	if !ok {
+		return zero, false
+	}
+	val, ok := res.(T)
+	if !ok {
+		return zero, false
+	}
+	return val, true
+}
+
+// RegisterOutputSink adds a sink that will receive all formatted entries
+// published on this context
+func (c *GadgetContext) RegisterOutputSink(sink OutputSink) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.outputSinks = append(c.outputSinks, sink)
+}
+
+// OutputSinks returns the registered output sinks
+func (c *GadgetContext) OutputSinks() []OutputSink {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return slices.Clone(c.outputSinks)
+}
+
+// PublishOutput delivers an entry to every registered sink; sink errors are
+// isolated per sink: a failing sink is logged but doesn't stop delivery to
+// the remaining ones
+func (c *GadgetContext) PublishOutput(entry []byte) {
+	for _, sink := range c.OutputSinks() {
+		if err := sink.Write(entry); err != nil {
+			c.Logger().Warnf("output sink %q: %v", sink.Name(), err)
+		}
+	}
+}
+
 func (c *GadgetContext) GetVars() map[string]any {
 	return maps.Clone(c.vars)
 }
@@ -242,6 +464,24 @@ func (c *GadgetContext) SerializeGadgetInfo() (*api.GadgetInfo, error) {
 		Params:    c.params,
 	}
 
+	c.lock.Lock()
+	for name := range c.exportableVars {
+		value, ok := c.vars[name]
+		if !ok {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			c.lock.Unlock()
+			return nil, fmt.Errorf("serializing var %q: %w", name, err)
+		}
+		if gi.Annotations == nil {
+			gi.Annotations = make(map[string]string)
+		}
+		gi.Annotations[exportedVarPrefix+name] = string(encoded)
+	}
+	c.lock.Unlock()
+
 	for _, ds := range c.GetDataSources() {
 		di := &api.DataSource{
 			Id:          0,
@@ -274,12 +514,28 @@ func (c *GadgetContext) LoadGadgetInfo(info *api.GadgetInfo, paramValues api.Par
 			c.lock.Unlock()
 			return fmt.Errorf("creating DataSource from API: %w", err)
 		}
+		c.instrumentDataSource(ds)
 		c.dataSources[inds.Name] = ds
 	}
 	c.params = info.Params
-	c.loaded = true
+	for key, encoded := range info.Annotations {
+		name, ok := strings.CutPrefix(key, exportedVarPrefix)
+		if !ok {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+			c.lock.Unlock()
+			return fmt.Errorf("restoring var %q: %w", name, err)
+		}
+		c.vars[name] = value
+	}
 	c.lock.Unlock()
 
+	if err := c.validateParamValues(paramValues); err != nil {
+		return fmt.Errorf("validating param values: %w", err)
+	}
+
 	c.Logger().Debug("loaded gadget info")
 
 	// After loading gadget info, start local operators as well
@@ -288,6 +544,12 @@ func (c *GadgetContext) LoadGadgetInfo(info *api.GadgetInfo, paramValues api.Par
 		return fmt.Errorf("initializing local operators: %w", err)
 	}
 
+	// only mark the context loaded once validation and operator init have
+	// succeeded, so a failed call can be retried
+	c.lock.Lock()
+	c.loaded = true
+	c.lock.Unlock()
+
 	if run {
 		go c.run(localOperators)
 	}
@@ -295,6 +557,48 @@ func (c *GadgetContext) LoadGadgetInfo(info *api.GadgetInfo, paramValues api.Par
 	return nil
 }
 
+// paramFullKey returns the key of a param including its prefix, matching the
+// keys callers use in api.ParamValues
+func paramFullKey(p *api.Param) string {
+	if p.Prefix == "" {
+		return p.Key
+	}
+	if strings.HasSuffix(p.Prefix, ".") {
+		return p.Prefix + p.Key
+	}
+	return p.Prefix + "." + p.Key
+}
+
+// validateParamValues checks the given param values against the params declared for this
+// gadget; unknown keys and missing mandatory params are aggregated into a single error so
+// typos don't silently get ignored
+func (c *GadgetContext) validateParamValues(paramValues api.ParamValues) error {
+	c.lock.Lock()
+	params := slices.Clone(c.params)
+	c.lock.Unlock()
+
+	known := make(map[string]*api.Param, len(params))
+	for _, p := range params {
+		known[paramFullKey(p)] = p
+	}
+
+	var errs []error
+	for key := range paramValues {
+		if _, ok := known[key]; !ok {
+			errs = append(errs, fmt.Errorf("unknown param %q", key))
+		}
+	}
+	for key, p := range known {
+		if !p.IsMandatory {
+			continue
+		}
+		if _, ok := paramValues[key]; !ok {
+			errs = append(errs, fmt.Errorf("missing mandatory param %q", key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func WithTimeoutOrCancel(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	if timeout == 0 {
 		return context.WithCancel(ctx)
@@ -302,8 +606,71 @@ func WithTimeoutOrCancel(ctx context.Context, timeout time.Duration) (context.Co
 	return context.WithTimeout(ctx, timeout)
 }
 
+// timeoutUpdateNotifier is implemented by contexts whose timeout can change
+// while it is being awaited (see GadgetContext.SetTimeout)
+type timeoutUpdateNotifier interface {
+	TimeoutUpdated() <-chan struct{}
+}
+
 func WaitForTimeoutOrDone(c gadgets.GadgetContext) {
-	ctx, cancel := WithTimeoutOrCancel(c.Context(), c.Timeout())
-	defer cancel()
-	<-ctx.Done()
+	WaitForTimeoutOrDoneWithReason(c)
+}
+
+// StopReason describes why a gadget run came to an end.
+type StopReason int
+
+const (
+	// ReasonCompleted means the gadget finished on its own
+	ReasonCompleted StopReason = iota
+	// ReasonTimeout means the configured timeout elapsed
+	ReasonTimeout
+	// ReasonCanceled means the parent context was canceled externally
+	ReasonCanceled
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonCanceled:
+		return "canceled"
+	default:
+		return "completed"
+	}
+}
+
+// WaitForTimeoutOrDoneWithReason blocks like WaitForTimeoutOrDone, but additionally reports
+// whether the gadget stopped because its timeout elapsed, the parent context was canceled or
+// it completed on its own; this lets callers distinguish "stopped after timeout" from
+// "interrupted".
+func WaitForTimeoutOrDoneWithReason(c gadgets.GadgetContext) StopReason {
+	var updated <-chan struct{}
+	if notifier, ok := c.(timeoutUpdateNotifier); ok {
+		updated = notifier.TimeoutUpdated()
+	}
+	start := time.Now()
+	for {
+		timeout := c.Timeout()
+		if timeout != 0 {
+			timeout -= time.Since(start)
+			if timeout <= 0 {
+				return ReasonTimeout
+			}
+		}
+		ctx, cancel := WithTimeoutOrCancel(c.Context(), timeout)
+		select {
+		case <-ctx.Done():
+			cancel()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ReasonTimeout
+			}
+			if errors.Is(c.Context().Err(), context.Canceled) {
+				return ReasonCanceled
+			}
+			return ReasonCompleted
+		case <-updated:
+			// SetTimeout was called; rearm with the new deadline
+			cancel()
+		}
+	}
 }
@@ -0,0 +1,290 @@
+// Copyright 2024 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetcontext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+type testLogger struct {
+	logger.Logger
+}
+
+func TestNewOCIOptions(t *testing.T) {
+	log := &testLogger{}
+	args := []string{"arg1", "arg2"}
+
+	c := NewOCI(context.Background(), "test-image", WithLogger(log), WithArgs(args))
+	assert.Equal(t, args, c.Args())
+	assert.Same(t, log, c.Logger())
+
+	// Without options, NewOCI falls back to sane defaults
+	c = NewOCI(context.Background(), "test-image")
+	assert.Empty(t, c.Args())
+	assert.NotNil(t, c.Logger())
+}
+
+func TestCancelWithReason(t *testing.T) {
+	c := NewOCI(context.Background(), "test-image")
+	assert.Nil(t, c.CancelCause())
+
+	cause := errors.New("eBPF verifier rejected program")
+	c.CancelWithReason(cause)
+	<-c.Context().Done()
+	assert.Equal(t, cause, c.CancelCause())
+
+	// A plain Cancel keeps the usual context.Canceled semantics
+	c = NewOCI(context.Background(), "test-image")
+	c.Cancel()
+	<-c.Context().Done()
+	assert.ErrorIs(t, c.CancelCause(), context.Canceled)
+}
+
+func TestValidateParamValues(t *testing.T) {
+	c := &GadgetContext{
+		params: []*api.Param{
+			{Key: "iface", Prefix: "operator.LocalManager."},
+			{Key: "mandatory", Prefix: "operator.LocalManager.", IsMandatory: true},
+			{Key: "noprefix"},
+		},
+	}
+
+	// All params known and mandatory ones provided
+	err := c.validateParamValues(api.ParamValues{
+		"operator.LocalManager.iface":     "eth0",
+		"operator.LocalManager.mandatory": "value",
+		"noprefix":                        "value",
+	})
+	assert.NoError(t, err)
+
+	// Unknown keys must be reported
+	err = c.validateParamValues(api.ParamValues{
+		"operator.LocalManager.ifac":      "eth0", // typo
+		"operator.LocalManager.mandatory": "value",
+	})
+	assert.ErrorContains(t, err, `unknown param "operator.LocalManager.ifac"`)
+
+	// Missing mandatory params must be reported
+	err = c.validateParamValues(api.ParamValues{})
+	assert.ErrorContains(t, err, `missing mandatory param "operator.LocalManager.mandatory"`)
+}
+
+func TestExportableVarsRoundTrip(t *testing.T) {
+	src := NewOCI(context.Background(), "test-image")
+	src.SetVar("internal", "operator-only state")
+	src.SetVar("mount-ns-map", map[string]any{"id": "host"})
+	src.SetVar("count", 42.0)
+	src.RegisterExportableVar("mount-ns-map")
+	src.RegisterExportableVar("count")
+	src.RegisterExportableVar("missing") // registered but never set
+
+	gi, err := src.SerializeGadgetInfo()
+	require.NoError(t, err)
+
+	// Only exportable vars that are set end up in the serialized info
+	assert.Contains(t, gi.Annotations, exportedVarPrefix+"mount-ns-map")
+	assert.Contains(t, gi.Annotations, exportedVarPrefix+"count")
+	assert.NotContains(t, gi.Annotations, exportedVarPrefix+"internal")
+	assert.NotContains(t, gi.Annotations, exportedVarPrefix+"missing")
+
+	dst := NewOCI(context.Background(), "test-image")
+	require.NoError(t, dst.LoadGadgetInfo(gi, nil, false))
+
+	value, ok := dst.GetVar("mount-ns-map")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"id": "host"}, value)
+	value, ok = dst.GetVar("count")
+	assert.True(t, ok)
+	assert.Equal(t, 42.0, value)
+	_, ok = dst.GetVar("internal")
+	assert.False(t, ok)
+}
+
+type testMetricsFactory struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (f *testMetricsFactory) NewCounter(name string) func() {
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.counts[name]++
+	}
+}
+
+func TestDataSourceStats(t *testing.T) {
+	factory := &testMetricsFactory{counts: map[string]int{}}
+	c := NewOCI(context.Background(), "test-image", WithMetricsFactory(factory))
+
+	ds, err := c.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		packet, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, ds.EmitAndRelease(packet))
+	}
+
+	// Both the internal counter and the factory counter see every emission
+	assert.Equal(t, map[string]uint64{"events": 3}, c.DataSourceStats())
+	assert.Equal(t, map[string]int{"events": 3}, factory.counts)
+
+	// Without a factory, no counting takes place
+	c = NewOCI(context.Background(), "test-image")
+	_, err = c.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	assert.Empty(t, c.DataSourceStats())
+}
+
+func TestSetTimeout(t *testing.T) {
+	c := NewOCI(context.Background(), "test-image")
+	c.SetTimeout(50 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, c.Timeout())
+
+	done := make(chan struct{})
+	go func() {
+		WaitForTimeoutOrDone(c)
+		close(done)
+	}()
+
+	// Lengthening the timeout while the wait is in progress keeps it running
+	// past the original deadline
+	time.Sleep(20 * time.Millisecond)
+	c.SetTimeout(250 * time.Millisecond)
+
+	select {
+	case <-done:
+		t.Fatal("wait returned before the lengthened timeout elapsed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("wait did not return after the lengthened timeout elapsed")
+	}
+}
+
+func TestClone(t *testing.T) {
+	src := NewOCI(context.Background(), "test-image")
+	original, err := src.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	src.SetVar("count", 42.0)
+
+	clone := src.Clone(context.Background())
+	assert.Equal(t, "test-image", clone.ImageName())
+	value, ok := clone.GetVar("count")
+	assert.True(t, ok)
+	assert.Equal(t, 42.0, value)
+
+	// The clone carries its own copy of the data sources
+	copied, ok := clone.GetDataSources()["events"]
+	require.True(t, ok)
+	assert.NotSame(t, original, copied)
+
+	// Registering on the clone leaves the original untouched and vice versa
+	_, err = clone.RegisterDataSource(datasource.TypeSingle, "extra")
+	require.NoError(t, err)
+	assert.NotContains(t, src.GetDataSources(), "extra")
+	_, err = src.RegisterDataSource(datasource.TypeSingle, "srconly")
+	require.NoError(t, err)
+	assert.NotContains(t, clone.GetDataSources(), "srconly")
+
+	// The clone starts out unloaded, so gadget info can be loaded again
+	gi, err := src.SerializeGadgetInfo()
+	require.NoError(t, err)
+	loaded := NewOCI(context.Background(), "test-image")
+	require.NoError(t, loaded.LoadGadgetInfo(gi, nil, false))
+	replay := loaded.Clone(context.Background())
+	require.NoError(t, replay.LoadGadgetInfo(gi, nil, false))
+}
+
+type testSink struct {
+	name    string
+	entries [][]byte
+	err     error
+}
+
+func (s *testSink) Name() string { return s.name }
+
+func (s *testSink) Write(entry []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestPublishOutputMultiplexing(t *testing.T) {
+	c := NewOCI(context.Background(), "test-image")
+
+	sink1 := &testSink{name: "sink1"}
+	sink2 := &testSink{name: "sink2"}
+	c.RegisterOutputSink(sink1)
+	c.RegisterOutputSink(sink2)
+
+	c.PublishOutput([]byte("event1"))
+	c.PublishOutput([]byte("event2"))
+
+	expected := [][]byte{[]byte("event1"), []byte("event2")}
+	assert.Equal(t, expected, sink1.entries)
+	assert.Equal(t, expected, sink2.entries)
+
+	// An error in one sink must not break delivery to the others
+	sink1.err = errors.New("sink is broken")
+	c.PublishOutput([]byte("event3"))
+	assert.Len(t, sink1.entries, 2)
+	assert.Equal(t, [][]byte{[]byte("event1"), []byte("event2"), []byte("event3")}, sink2.entries)
+}
+
+func TestCallDataOperatorRecoversPanic(t *testing.T) {
+	// A panicking operator is converted into an error instead of crashing
+	err := callDataOperator("broken", func() error {
+		panic("something went wrong")
+	})
+	var panicErr *operatorPanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.ErrorContains(t, err, `operator "broken" panicked: something went wrong`)
+	// The stack trace is part of the error for debugging
+	assert.Contains(t, err.Error(), "goroutine")
+
+	// The run continues with the remaining operators
+	called := false
+	_ = callDataOperator("broken", func() error {
+		panic("something went wrong")
+	})
+	err = callDataOperator("healthy", func() error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	// Regular errors pass through untouched
+	opErr := errors.New("regular failure")
+	err = callDataOperator("failing", func() error { return opErr })
+	assert.Equal(t, opErr, err)
+}
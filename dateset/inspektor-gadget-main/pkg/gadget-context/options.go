@@ -23,12 +23,32 @@ import (
 
 type Option func(gadgetCtx *GadgetContext)
 
+// WithLogger sets the logger used by the gadget context and everything running on it; if it is
+// not given, NewOCI falls back to logger.DefaultLogger()
 func WithLogger(logger logger.Logger) Option {
 	return func(gadgetCtx *GadgetContext) {
 		gadgetCtx.logger = logger
 	}
 }
 
+// WithArgs sets the arguments that are handed over to the gadget, as they would be given on the
+// command line after the image name
+func WithArgs(args []string) Option {
+	return func(gadgetCtx *GadgetContext) {
+		gadgetCtx.args = args
+	}
+}
+
+// WithMetricsFactory enables instrumentation of the gadget run: every data source registered
+// on the context gets a counter from the factory that is incremented per emitted record; see
+// also DataSourceStats()
+func WithMetricsFactory(factory MetricsFactory) Option {
+	return func(gadgetCtx *GadgetContext) {
+		gadgetCtx.metricsFactory = factory
+	}
+}
+
+// WithDataOperators sets the data operators to use when running the gadget
 func WithDataOperators(ops ...operators.DataOperator) Option {
 	return func(gadgetCtx *GadgetContext) {
 		gadgetCtx.dataOperators = slices.Clone(ops)
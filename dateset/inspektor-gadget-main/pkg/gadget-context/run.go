@@ -15,7 +15,9 @@
 package gadgetcontext
 
 import (
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sort"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -89,28 +91,78 @@ func (c *GadgetContext) initAndPrepareOperators(paramValues api.ParamValues) ([]
 	return dataOperatorInstances, nil
 }
 
+// operatorPanicError is returned by callDataOperator when the operator
+// panicked instead of returning; it carries the recovered value and the stack
+// trace of the panicking goroutine.
+type operatorPanicError struct {
+	name  string
+	value any
+	stack []byte
+}
+
+func (e *operatorPanicError) Error() string {
+	return fmt.Sprintf("operator %q panicked: %v\n%s", e.name, e.value, e.stack)
+}
+
+// callDataOperator invokes fn and converts a panic inside the operator into an
+// *operatorPanicError, so a faulty operator cannot crash the whole process.
+func callDataOperator(name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &operatorPanicError{name: name, value: r, stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
 func (c *GadgetContext) run(dataOperatorInstances []operators.DataOperatorInstance) error {
 	log := c.Logger()
 
+	// Operators that panicked are disabled for the rest of the run; their
+	// panics are accumulated and reported once the run finishes.
+	disabled := make(map[string]bool)
+	var panicErrs []error
+	disableOnPanic := func(opInst operators.DataOperatorInstance, phase string, err error) bool {
+		var panicErr *operatorPanicError
+		if !errors.As(err, &panicErr) {
+			return false
+		}
+		log.Errorf("%s operator %q: %v", phase, opInst.Name(), err)
+		disabled[opInst.Name()] = true
+		panicErrs = append(panicErrs, fmt.Errorf("%s operator %q: %w", phase, opInst.Name(), err))
+		return true
+	}
+
 	for _, opInst := range dataOperatorInstances {
 		preStart, ok := opInst.(operators.PreStart)
 		if !ok {
 			continue
 		}
 		log.Debugf("pre-starting op %q", opInst.Name())
-		err := preStart.PreStart(c)
+		err := callDataOperator(opInst.Name(), func() error { return preStart.PreStart(c) })
+		if disableOnPanic(opInst, "pre-starting", err) {
+			continue
+		}
 		if err != nil {
-			c.cancel()
-			return fmt.Errorf("pre-starting operator %q: %w", opInst.Name(), err)
+			err = fmt.Errorf("pre-starting operator %q: %w", opInst.Name(), err)
+			c.cancel(err)
+			return err
 		}
 	}
 
 	for _, opInst := range dataOperatorInstances {
+		if disabled[opInst.Name()] {
+			continue
+		}
 		log.Debugf("starting op %q", opInst.Name())
-		err := opInst.Start(c)
+		err := callDataOperator(opInst.Name(), func() error { return opInst.Start(c) })
+		if disableOnPanic(opInst, "starting", err) {
+			continue
+		}
 		if err != nil {
-			c.cancel()
-			return fmt.Errorf("starting operator %q: %w", opInst.Name(), err)
+			err = fmt.Errorf("starting operator %q: %w", opInst.Name(), err)
+			c.cancel(err)
+			return err
 		}
 	}
 
@@ -121,8 +173,14 @@ func (c *GadgetContext) run(dataOperatorInstances []operators.DataOperatorInstan
 	// Stop/DeInit in reverse order
 	for i := len(dataOperatorInstances) - 1; i >= 0; i-- {
 		opInst := dataOperatorInstances[i]
+		if disabled[opInst.Name()] {
+			continue
+		}
 		log.Debugf("stopping op %q", opInst.Name())
-		err := opInst.Stop(c)
+		err := callDataOperator(opInst.Name(), func() error { return opInst.Stop(c) })
+		if disableOnPanic(opInst, "stopping", err) {
+			continue
+		}
 		if err != nil {
 			log.Errorf("stopping operator %q: %v", opInst.Name(), err)
 		}
@@ -131,17 +189,23 @@ func (c *GadgetContext) run(dataOperatorInstances []operators.DataOperatorInstan
 	// Stop/DeInit in reverse order
 	for i := len(dataOperatorInstances) - 1; i >= 0; i-- {
 		opInst := dataOperatorInstances[i]
+		if disabled[opInst.Name()] {
+			continue
+		}
 		postStop, ok := opInst.(operators.PostStop)
 		if !ok {
 			continue
 		}
 		log.Debugf("post-stopping op %q", opInst.Name())
-		err := postStop.PostStop(c)
+		err := callDataOperator(opInst.Name(), func() error { return postStop.PostStop(c) })
+		if disableOnPanic(opInst, "post-stopping", err) {
+			continue
+		}
 		if err != nil {
 			log.Errorf("post-stopping operator %q: %v", opInst.Name(), err)
 		}
 	}
-	return nil
+	return errors.Join(panicErrs...)
 }
 
 func (c *GadgetContext) PrepareGadgetInfo(paramValues api.ParamValues) error {
@@ -152,8 +216,9 @@ func (c *GadgetContext) PrepareGadgetInfo(paramValues api.ParamValues) error {
 func (c *GadgetContext) Run(paramValues api.ParamValues) error {
 	dataOperatorInstances, err := c.initAndPrepareOperators(paramValues)
 	if err != nil {
-		c.cancel()
-		return fmt.Errorf("initializing and preparing operators: %w", err)
+		err = fmt.Errorf("initializing and preparing operators: %w", err)
+		c.cancel(err)
+		return err
 	}
 	return c.run(dataOperatorInstances)
 }
@@ -67,6 +67,17 @@ type ESStorageIntegration struct {
 	v8Client      *elasticsearch8.Client
 	bulkProcessor *elastic.BulkProcessor
 	logger        *zap.Logger
+
+	// indexPrefixSeparator joins the index prefix and the index name; empty
+	// means the default "-".
+	indexPrefixSeparator string
+}
+
+func (s *ESStorageIntegration) prefixSeparator() string {
+	if s.indexPrefixSeparator == "" {
+		return "-"
+	}
+	return s.indexPrefixSeparator
 }
 
 func (s *ESStorageIntegration) tracerProvider() (trace.TracerProvider, *tracetest.InMemoryExporter, func()) {
@@ -143,6 +154,7 @@ func (s *ESStorageIntegration) initSamplingStore(t *testing.T) {
 		Replicas:        1,
 		EsVersion:       client.GetVersion(),
 		IndexPrefix:     indexPrefix,
+		PrefixSeparator: s.prefixSeparator(),
 		UseILM:          false,
 	}
 	clientFn := func() estemplate.Client { return client }
@@ -178,6 +190,7 @@ func (s *ESStorageIntegration) initSpanstore(t *testing.T, allTagsAsFields, arch
 		Replicas:        1,
 		EsVersion:       client.GetVersion(),
 		IndexPrefix:     indexPrefix,
+		PrefixSeparator: s.prefixSeparator(),
 		UseILM:          false,
 	}
 	spanMapping, serviceMapping, err := mappingBuilder.GetSpanServiceMappings()
@@ -186,13 +199,14 @@ func (s *ESStorageIntegration) initSpanstore(t *testing.T, allTagsAsFields, arch
 
 	w := spanstore.NewSpanWriter(
 		spanstore.SpanWriterParams{
-			Client:            clientFn,
-			Logger:            s.logger,
-			MetricsFactory:    metrics.NullFactory,
-			IndexPrefix:       indexPrefix,
-			AllTagsAsFields:   allTagsAsFields,
-			TagDotReplacement: tagKeyDeDotChar,
-			Archive:           archive,
+			Client:               clientFn,
+			Logger:               s.logger,
+			MetricsFactory:       metrics.NullFactory,
+			IndexPrefix:          indexPrefix,
+			IndexPrefixSeparator: s.prefixSeparator(),
+			AllTagsAsFields:      allTagsAsFields,
+			TagDotReplacement:    tagKeyDeDotChar,
+			Archive:              archive,
 		})
 	err = w.CreateTemplates(spanMapping, serviceMapping, indexPrefix)
 	require.NoError(t, err)
@@ -200,15 +214,16 @@ func (s *ESStorageIntegration) initSpanstore(t *testing.T, allTagsAsFields, arch
 	defer closer()
 	s.SpanWriter = w
 	s.SpanReader = spanstore.NewSpanReader(spanstore.SpanReaderParams{
-		Client:            clientFn,
-		Logger:            s.logger,
-		MetricsFactory:    metrics.NullFactory,
-		IndexPrefix:       indexPrefix,
-		MaxSpanAge:        maxSpanAge,
-		TagDotReplacement: tagKeyDeDotChar,
-		Archive:           archive,
-		MaxDocCount:       defaultMaxDocCount,
-		Tracer:            tracer.Tracer("test"),
+		Client:               clientFn,
+		Logger:               s.logger,
+		MetricsFactory:       metrics.NullFactory,
+		IndexPrefix:          indexPrefix,
+		IndexPrefixSeparator: s.prefixSeparator(),
+		MaxSpanAge:           maxSpanAge,
+		TagDotReplacement:    tagKeyDeDotChar,
+		Archive:              archive,
+		MaxDocCount:          defaultMaxDocCount,
+		Tracer:               tracer.Tracer("test"),
 	})
 	dependencyStore := dependencystore.NewDependencyStore(dependencystore.DependencyStoreParams{
 		Client:          clientFn,
@@ -277,6 +292,18 @@ func TestElasticsearchStorage_Archive(t *testing.T) {
 	testElasticsearchStorage(t, false, true)
 }
 
+func TestElasticsearchStorage_CustomIndexPrefixSeparator(t *testing.T) {
+	if os.Getenv("STORAGE") != "elasticsearch" && os.Getenv("STORAGE") != "opensearch" {
+		t.Skip("Integration test against ElasticSearch skipped; set STORAGE env var to elasticsearch to run this")
+	}
+	if err := healthCheck(); err != nil {
+		t.Fatal(err)
+	}
+	s := &ESStorageIntegration{indexPrefixSeparator: "_"}
+	s.initializeES(t, true, false)
+	t.Run("GetOperations", s.testGetOperations)
+}
+
 func TestElasticsearchStorage_IndexTemplates(t *testing.T) {
 	if os.Getenv("STORAGE") != "elasticsearch" {
 		t.Skip("Integration test against ElasticSearch skipped; set STORAGE env var to elasticsearch to run this")
@@ -339,7 +366,7 @@ func (s *ESStorageIntegration) cleanESIndexTemplates(t *testing.T, prefix string
 	if version > 7 {
 		prefixWithSeparator := prefix
 		if prefix != "" {
-			prefixWithSeparator += "-"
+			prefixWithSeparator += s.prefixSeparator()
 		}
 		_, err := s.v8Client.Indices.DeleteIndexTemplate(prefixWithSeparator + spanTemplateName)
 		require.NoError(t, err)
@@ -23,6 +23,7 @@ import (
 	"os/exec"
 	"strconv"
 	"testing"
+	"time"
 
 	elasticsearch8 "github.com/elastic/go-elasticsearch/v8"
 	"github.com/olivere/elastic"
@@ -154,6 +155,65 @@ func runIndexRolloverWithILMTest(t *testing.T, client *elastic.Client, prefix st
 	assert.ElementsMatch(t, actualWriteAliases, expectedWriteAliases, fmt.Sprintf("aliases found: %v, expected: %v", actualWriteAliases, expectedWriteAliases))
 }
 
+func TestIndexRollover_RolloverBySize(t *testing.T) {
+	client, err := createESClient()
+	require.NoError(t, err)
+	esVersion, err := getVersion(client)
+	require.NoError(t, err)
+	if esVersion < 7 {
+		t.Skip("Integration test - " + t.Name() + " against ElasticSearch skipped for ES version " + fmt.Sprint(esVersion))
+	}
+	// make sure ES is cleaned before and after the test
+	cleanES(t, client, defaultILMPolicyName)
+	v8Client, err := createESV8Client()
+	require.NoError(t, err)
+	defer cleanES(t, client, defaultILMPolicyName)
+	defer cleanESIndexTemplates(t, client, v8Client, "")
+
+	// a 1b max_size makes any non-empty index eligible for rollover, so a
+	// handful of spans stands in for the N GB a production policy would use
+	err = createILMPolicyWithMaxSize(client, defaultILMPolicyName, "1b")
+	require.NoError(t, err)
+	// ILM checks rollover conditions every 10 minutes by default, which is far
+	// too slow for an integration test
+	_, err = client.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_cluster/settings",
+		Body:   `{"transient": {"indices.lifecycle.poll_interval": "1s"}}`,
+	})
+	require.NoError(t, err)
+
+	err = runEsRollover("init", []string{"ES_USE_ILM=true"}, false)
+	require.NoError(t, err)
+
+	// write spans through the write alias until the index exceeds max_size
+	for i := 0; i < 10; i++ {
+		_, err := client.Index().Index("jaeger-span-write").BodyJson(map[string]interface{}{
+			"traceID":       fmt.Sprintf("%d", i),
+			"spanID":        "1",
+			"operationName": "size-rollover",
+		}).Do(context.Background())
+		require.NoError(t, err)
+	}
+	_, err = client.Refresh().Do(context.Background())
+	require.NoError(t, err)
+
+	// ILM rolls the write alias over to a second index once it notices the
+	// size condition is met
+	assert.Eventually(t, func() bool {
+		indices, err := client.IndexNames()
+		if err != nil {
+			return false
+		}
+		for _, index := range indices {
+			if index == "jaeger-span-000002" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Minute, 5*time.Second, "expected a second span index after size-based rollover")
+}
+
 func createESClient() (*elastic.Client, error) {
 	return elastic.NewClient(
 		elastic.SetURL(queryURL),
@@ -196,6 +256,12 @@ func createILMPolicy(client *elastic.Client, policyName string) error {
 	return err
 }
 
+func createILMPolicyWithMaxSize(client *elastic.Client, policyName string, maxSize string) error {
+	body := fmt.Sprintf(`{"policy": {"phases": {"hot": {"min_age": "0ms","actions": {"rollover": {"max_size": %q},"set_priority": {"priority": 100}}}}}}`, maxSize)
+	_, err := client.XPackIlmPutLifecycle().Policy(policyName).BodyString(body).Do(context.Background())
+	return err
+}
+
 func cleanES(t *testing.T, client *elastic.Client, policyName string) {
 	_, err := client.DeleteIndex("*").Do(context.Background())
 	require.NoError(t, err)
@@ -26,3 +26,20 @@ func (cluster *Cluster) StartDDNS(ctx context.Context) error {
 
 	return nil
 }
+
+// AdvertiseDDNS pushes the current VIP address for every DDNS-enabled network
+// to the external dynamic DNS provider. It should be called after this node
+// takes over the VIP (initial start and failover) so that clients resolving
+// the DNS name follow the address
+func (cluster *Cluster) AdvertiseDDNS(ctx context.Context, provider vip.DDNSProvider) error {
+	for i := range cluster.Network {
+		if !cluster.Network[i].IsDDNS() {
+			continue
+		}
+		if err := provider.Update(ctx, cluster.Network[i].DDNSHostName(), cluster.Network[i].IP()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kube-vip/kube-vip/pkg/vip"
+	"github.com/vishvananda/netlink"
+	v1 "k8s.io/api/core/v1"
+)
+
+// fakeDDNSNetwork implements vip.Network with just enough state to exercise
+// the DDNS advertisement path
+type fakeDDNSNetwork struct {
+	ip       string
+	hostname string
+	isDDNS   bool
+}
+
+func (f *fakeDDNSNetwork) AddIP() error                  { return nil }
+func (f *fakeDDNSNetwork) AddRoute() error               { return nil }
+func (f *fakeDDNSNetwork) DeleteIP() error               { return nil }
+func (f *fakeDDNSNetwork) DeleteRoute() error            { return nil }
+func (f *fakeDDNSNetwork) UpdateRoutes() (bool, error)   { return false, nil }
+func (f *fakeDDNSNetwork) IsSet() (bool, error)          { return true, nil }
+func (f *fakeDDNSNetwork) IP() string                    { return f.ip }
+func (f *fakeDDNSNetwork) PrepareRoute() *netlink.Route  { return nil }
+func (f *fakeDDNSNetwork) SetIP(ip string) error         { f.ip = ip; return nil }
+func (f *fakeDDNSNetwork) SetRouteMTU(_ int)             {}
+func (f *fakeDDNSNetwork) SetServicePorts(_ *v1.Service) {}
+func (f *fakeDDNSNetwork) Interface() string             { return "lo" }
+func (f *fakeDDNSNetwork) IsDADFAIL() bool               { return false }
+func (f *fakeDDNSNetwork) IsDNS() bool                   { return false }
+func (f *fakeDDNSNetwork) IsDDNS() bool                  { return f.isDDNS }
+func (f *fakeDDNSNetwork) DDNSHostName() string          { return f.hostname }
+func (f *fakeDDNSNetwork) DNSName() string               { return f.hostname }
+
+// fakeDDNSProvider records the updates it receives
+type fakeDDNSProvider struct {
+	updates map[string]string
+}
+
+func (f *fakeDDNSProvider) Update(_ context.Context, hostname, ip string) error {
+	f.updates[hostname] = ip
+	return nil
+}
+
+func TestAdvertiseDDNS(t *testing.T) {
+	network := &fakeDDNSNetwork{ip: "192.168.0.10", hostname: "vip.example.com", isDDNS: true}
+	skipped := &fakeDDNSNetwork{ip: "192.168.0.20", hostname: "static.example.com"}
+	cluster := &Cluster{Network: []vip.Network{network, skipped}}
+	provider := &fakeDDNSProvider{updates: map[string]string{}}
+
+	if err := cluster.AdvertiseDDNS(context.TODO(), provider); err != nil {
+		t.Fatalf("AdvertiseDDNS() error = %v", err)
+	}
+	if got := provider.updates["vip.example.com"]; got != "192.168.0.10" {
+		t.Errorf("AdvertiseDDNS() sent ip %s, want 192.168.0.10", got)
+	}
+	if _, ok := provider.updates["static.example.com"]; ok {
+		t.Errorf("AdvertiseDDNS() should skip networks without DDNS")
+	}
+
+	// After a failover the VIP moves; the provider must receive the new address
+	if err := network.SetIP("192.168.0.11"); err != nil {
+		t.Fatalf("SetIP() error = %v", err)
+	}
+	if err := cluster.AdvertiseDDNS(context.TODO(), provider); err != nil {
+		t.Fatalf("AdvertiseDDNS() error = %v", err)
+	}
+	if got := provider.updates["vip.example.com"]; got != "192.168.0.11" {
+		t.Errorf("AdvertiseDDNS() sent ip %s after failover, want 192.168.0.11", got)
+	}
+}
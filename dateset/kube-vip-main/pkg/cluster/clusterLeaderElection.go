@@ -16,6 +16,7 @@ import (
 	"github.com/kube-vip/kube-vip/pkg/k8s"
 	"github.com/kube-vip/kube-vip/pkg/kubevip"
 	"github.com/kube-vip/kube-vip/pkg/loadbalancer"
+	"github.com/kube-vip/kube-vip/pkg/vip"
 
 	"github.com/packethost/packngo"
 
@@ -180,6 +181,18 @@ func (cluster *Cluster) StartCluster(c *kubevip.Config, sm *Manager, bgpServer *
 			if err != nil {
 				log.Errorf("Error starting the VIP service on the leader [%s]", err)
 			}
+			// Push the VIP address to the external dynamic DNS provider, so
+			// clients resolving the DNS name follow the address across failover
+			if c.DDNSProviderEndpoint != "" {
+				provider := vip.NewWebDDNSProvider(vip.DDNSProviderConfig{
+					Endpoint: c.DDNSProviderEndpoint,
+					Username: c.DDNSProviderUsername,
+					Password: c.DDNSProviderPassword,
+				})
+				if err := cluster.AdvertiseDDNS(ctx, provider); err != nil {
+					log.Errorf("Error advertising the VIP to the DDNS provider [%s]", err)
+				}
+			}
 		},
 		onStoppedLeading: func() {
 			// we can do cleanup here
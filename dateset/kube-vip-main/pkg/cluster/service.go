@@ -3,8 +3,10 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -70,9 +72,15 @@ func (cluster *Cluster) vipService(ctxArp, ctxDNS context.Context, c *kubevip.Co
 			cidrVip := fmt.Sprintf("%s/%s", cluster.Network[i].IP(), c.VIPCIDR)
 			log.Debugf("Attempting to advertise the address [%s] over BGP", cidrVip)
 
-			err = bgpServer.AddHost(cidrVip)
-			if err != nil {
-				log.Error(err)
+			if c.EnableBGPHealthCheck {
+				// Only advertise the VIP while the local service answers on its
+				// port, so an unhealthy node never attracts traffic over BGP
+				go cluster.bgpHealthCheck(ctxArp, bgpServer, cidrVip, c)
+			} else {
+				err = bgpServer.AddHost(cidrVip)
+				if err != nil {
+					log.Error(err)
+				}
 			}
 		}
 
@@ -120,7 +128,7 @@ func (cluster *Cluster) vipService(ctxArp, ctxDNS context.Context, c *kubevip.Co
 				if ndp != nil {
 					defer ndp.Close()
 				}
-				log.Infof("Gratuitous Arp broadcast will repeat every 3 seconds for [%s]", ipString)
+				log.Infof("Gratuitous Arp broadcast will repeat every %dms for [%s]", c.ArpBroadcastRate, ipString)
 				for {
 					select {
 					case <-ctx.Done(): // if cancel() execute
@@ -128,7 +136,11 @@ func (cluster *Cluster) vipService(ctxArp, ctxDNS context.Context, c *kubevip.Co
 					default:
 						cluster.ensureIPAndSendGratuitous(c.Interface, ndp)
 					}
-					time.Sleep(3 * time.Second)
+					if c.ArpBroadcastRate < 500 {
+						log.Errorf("arp broadcast rate is [%d], this shouldn't be lower that 300ms (defaulting to 3000)", c.ArpBroadcastRate)
+						c.ArpBroadcastRate = 3000
+					}
+					time.Sleep(time.Duration(c.ArpBroadcastRate) * time.Millisecond)
 				}
 			}(ctxArp)
 		}
@@ -144,6 +156,62 @@ func (cluster *Cluster) vipService(ctxArp, ctxDNS context.Context, c *kubevip.Co
 	return nil
 }
 
+// bgpHealthCheck gates the BGP advertisement of a VIP behind a local TCP
+// readiness probe: the host is only added once the service answers on its port
+// and is withdrawn again should the probe start failing. The loop ends when the
+// context is cancelled (e.g. on losing leadership), leaving the final cleanup
+// to the regular step-down path.
+func (cluster *Cluster) bgpHealthCheck(ctx context.Context, bgpServer *bgp.Server, cidrVip string, c *kubevip.Config) {
+	port := c.BGPHealthCheckPort
+	if port == 0 {
+		port = c.Port
+	}
+	interval := c.BGPHealthCheckInterval
+	if interval == 0 {
+		interval = 5
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	log.Infof("Starting BGP health check for [%s] against [%s] every %ds", cidrVip, addr, interval)
+
+	advertised := false
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		healthy := tcpProbe(addr, 2*time.Second)
+		if healthy && !advertised {
+			log.Infof("Health check for [%s] passed, advertising [%s] over BGP", addr, cidrVip)
+			if err := bgpServer.AddHost(cidrVip); err != nil {
+				log.Error(err)
+			} else {
+				advertised = true
+			}
+		}
+		if !healthy && advertised {
+			log.Warnf("Health check for [%s] failed, withdrawing [%s] from BGP", addr, cidrVip)
+			if err := bgpServer.DelHost(cidrVip); err != nil {
+				log.Error(err)
+			} else {
+				advertised = false
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tcpProbe reports whether a TCP connection to addr can be established within timeout
+func tcpProbe(addr string, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
 // StartLoadBalancerService will start a VIP instance and leave it for kube-proxy to handle
 func (cluster *Cluster) StartLoadBalancerService(c *kubevip.Config, bgp *bgp.Server) {
 	// use a Go context so we can tell the arp loop code when we
@@ -237,6 +305,10 @@ func (cluster *Cluster) StartLoadBalancerService(c *kubevip.Config, bgp *bgp.Ser
 
 		log.Info("[LOADBALANCER] Stopping load balancers")
 
+		// Send a final unsolicited advertisement so that upstream switches learn
+		// the VIP has moved, instead of blackholing traffic until their caches expire
+		cluster.sendFinalGratuitous(c.Interface)
+
 		for i := range cluster.Network {
 			log.Infof("[VIP] Releasing the Virtual IP [%s]", cluster.Network[i].IP())
 			if err := cluster.Network[i].DeleteIP(); err != nil {
@@ -248,6 +320,30 @@ func (cluster *Cluster) StartLoadBalancerService(c *kubevip.Config, bgp *bgp.Ser
 	}()
 }
 
+// sendFinalGratuitous - sends a single best-effort gratuitous ARP (or NDP for
+// IPv6) for each VIP before it is released, so the network learns the address
+// is no longer owned by this node.
+func (cluster *Cluster) sendFinalGratuitous(iface string) {
+	for i := range cluster.Network {
+		ipString := cluster.Network[i].IP()
+		if vip.IsIPv6(ipString) {
+			ndp, err := vip.NewNDPResponder(iface)
+			if err != nil {
+				log.Warnf("failed to create NDP Responder for final advertisement: %v", err)
+				continue
+			}
+			if err := ndp.SendGratuitous(ipString); err != nil {
+				log.Warnf("%v", err)
+			}
+			ndp.Close()
+		} else {
+			if err := vip.ARPSendGratuitous(ipString, iface); err != nil {
+				log.Warnf("%v", err)
+			}
+		}
+	}
+}
+
 // ensureIPAndSendGratuitous - adds IP to the interface if missing, and send
 // either a gratuitous ARP or gratuitous NDP. Re-adds the interface if it is IPv6
 // and in a dadfailed state.
@@ -0,0 +1,26 @@
+package cluster
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_tcpProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	if !tcpProbe(listener.Addr().String(), time.Second) {
+		t.Errorf("expected probe against a listening socket to pass")
+	}
+
+	// Closing the listener frees the port, so the probe must fail
+	addr := listener.Addr().String()
+	listener.Close()
+	if tcpProbe(addr, time.Second) {
+		t.Errorf("expected probe against a closed socket to fail")
+	}
+}
@@ -0,0 +1,299 @@
+package kubevip
+
+import (
+	"os"
+	"strconv"
+)
+
+// ParseEnvironment will populate the configuration from the environment
+func ParseEnvironment(c *Config) error {
+	if c == nil {
+		return nil
+	}
+
+	// Find the configuration for ARP
+	env := os.Getenv(vipArp)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableARP = b
+	}
+
+	env = os.Getenv(vipArpRate)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return err
+		}
+		c.ArpBroadcastRate = i
+	}
+
+	// Find the interface configuration
+	env = os.Getenv(vipInterface)
+	if env != "" {
+		c.Interface = env
+	}
+
+	env = os.Getenv(vipServicesInterface)
+	if env != "" {
+		c.ServicesInterface = env
+	}
+
+	// Find the address configuration
+	env = os.Getenv(vipAddress)
+	if env != "" {
+		// TODO - parse address net.Host()
+		c.VIP = env
+	}
+
+	env = os.Getenv(address)
+	if env != "" {
+		c.Address = env
+	}
+
+	env = os.Getenv(port)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.Port = int(i)
+	}
+
+	env = os.Getenv(vipDdns)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.DDNS = b
+	}
+
+	env = os.Getenv(ddnsProviderEndpoint)
+	if env != "" {
+		c.DDNSProviderEndpoint = env
+	}
+
+	env = os.Getenv(ddnsProviderUsername)
+	if env != "" {
+		c.DDNSProviderUsername = env
+	}
+
+	env = os.Getenv(ddnsProviderPassword)
+	if env != "" {
+		c.DDNSProviderPassword = env
+	}
+
+	env = os.Getenv(vipCidr)
+	if env != "" {
+		c.VIPCIDR = env
+	}
+
+	env = os.Getenv(vipSubnet)
+	if env != "" {
+		c.VIPSubnet = env
+	}
+
+	// Find the leader election configuration
+	env = os.Getenv(vipLeaderElection)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableLeaderElection = b
+	}
+
+	env = os.Getenv(vipLeaseName)
+	if env != "" {
+		c.LeaseName = env
+	}
+
+	env = os.Getenv(vipLeaseDuration)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.LeaseDuration = int(i)
+	}
+
+	env = os.Getenv(vipRenewDeadline)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.RenewDeadline = int(i)
+	}
+
+	env = os.Getenv(vipRetryPeriod)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.RetryPeriod = int(i)
+	}
+
+	env = os.Getenv(cpNamespace)
+	if env != "" {
+		c.Namespace = env
+	}
+
+	// Find the services configuration
+	env = os.Getenv(svcEnable)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableServices = b
+	}
+
+	env = os.Getenv(svcNamespace)
+	if env != "" {
+		c.ServiceNamespace = env
+	}
+
+	env = os.Getenv(svcElection)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableServicesElection = b
+	}
+
+	env = os.Getenv(svcLeaseName)
+	if env != "" {
+		c.ServicesLeaseName = env
+	}
+
+	env = os.Getenv(enableEndpointSlices)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableEndpointSlices = b
+	}
+
+	env = os.Getenv(enableNodeLabeling)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableNodeLabeling = b
+	}
+
+	env = os.Getenv(nodeLabel)
+	if env != "" {
+		c.NodeLabel = env
+	}
+
+	// Find the BGP configuration
+	env = os.Getenv(bgpEnable)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableBGP = b
+	}
+
+	env = os.Getenv(bgpHealthCheck)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableBGPHealthCheck = b
+	}
+
+	env = os.Getenv(bgpHealthCheckPort)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.BGPHealthCheckPort = int(i)
+	}
+
+	env = os.Getenv(bgpHealthCheckInterval)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.BGPHealthCheckInterval = int(i)
+	}
+
+	// Find the load-balancer configuration
+	env = os.Getenv(lbEnable)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EnableLoadBalancer = b
+	}
+
+	env = os.Getenv(lbPort)
+	if env != "" {
+		i, err := strconv.ParseInt(env, 10, 32)
+		if err != nil {
+			return err
+		}
+		c.LoadBalancerPort = int(i)
+	}
+
+	env = os.Getenv(lbForwardingMethod)
+	if env != "" {
+		c.LoadBalancerForwardingMethod = env
+	}
+
+	// Find the egress configuration
+	env = os.Getenv(egressPodCidr)
+	if env != "" {
+		c.EgressPodCidr = env
+	}
+
+	env = os.Getenv(egressServiceCidr)
+	if env != "" {
+		c.EgressServiceCidr = env
+	}
+
+	env = os.Getenv(egressWithNftables)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EgressWithNftables = b
+	}
+
+	env = os.Getenv(egressDryRun)
+	if env != "" {
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		c.EgressDryRun = b
+	}
+
+	env = os.Getenv(egressFWMark)
+	if env != "" {
+		c.EgressFWMark = env
+	}
+
+	env = os.Getenv(prometheusServer)
+	if env != "" {
+		c.PrometheusHTTPServer = env
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package kubevip
+
+// Environment variables
+const (
+	// vipArp - defines if the arp broadcast should be enabled
+	vipArp = "vip_arp"
+
+	// vipArpRate - defines the rate of gARP broadcasts
+	vipArpRate = "vip_arpRate"
+
+	// vipLeaderElection - defines if the kubernetes algorithm should be used
+	vipLeaderElection = "vip_leaderelection"
+
+	// vipLeaseName - defines the name of the lease lock
+	vipLeaseName = "vip_leasename"
+
+	// vipLeaseDuration - defines the length of time a lease can be held for
+	vipLeaseDuration = "vip_leaseduration"
+
+	// vipRenewDeadline - defines the length of time a host can attempt to renew its lease
+	vipRenewDeadline = "vip_renewdeadline"
+
+	// vipRetryPeriod - defines the number of times the host will retry to hold a lease
+	vipRetryPeriod = "vip_retryperiod"
+
+	// vipAddress - defines the address that the vip will expose
+	vipAddress = "vip_address"
+
+	// address - defines the address (IP or DNS name) that the vip will expose
+	address = "address"
+
+	// port - defines the port for the VIP
+	port = "port"
+
+	// vipDdns - defines if DDNS is required
+	vipDdns = "vip_ddns"
+
+	// ddnsProviderEndpoint - defines the update URL of the dynamic DNS provider
+	ddnsProviderEndpoint = "ddns_provider_endpoint"
+
+	// ddnsProviderUsername - defines the username for the dynamic DNS provider
+	ddnsProviderUsername = "ddns_provider_username"
+
+	// ddnsProviderPassword - defines the password for the dynamic DNS provider
+	ddnsProviderPassword = "ddns_provider_password"
+
+	// vipCidr - defines the cidr that the vip will use (for BGP)
+	vipCidr = "vip_cidr"
+
+	// vipSubnet - defines the subnet that the vip will use
+	vipSubnet = "vip_subnet"
+
+	// vipInterface - defines the interface that the vip should bind to
+	vipInterface = "vip_interface"
+
+	// vipServicesInterface - defines the interface that services should bind to
+	vipServicesInterface = "vip_servicesinterface"
+
+	// cpNamespace - defines the namespace the control plane pods will run in
+	cpNamespace = "cp_namespace"
+
+	// svcEnable - defines if the service watcher is enabled
+	svcEnable = "svc_enable"
+
+	// svcNamespace - defines the namespace the services are watched in
+	svcNamespace = "svc_namespace"
+
+	// svcElection - defines if leader election per service is enabled
+	svcElection = "svc_election"
+
+	// svcLeaseName - defines the lease name used for the services leader election
+	svcLeaseName = "svc_leasename"
+
+	// bgpEnable - defines if BGP should be enabled
+	bgpEnable = "bgp_enable"
+
+	// bgpHealthCheck - defines if the BGP advertisement is gated behind a local probe
+	bgpHealthCheck = "bgp_healthcheck"
+
+	// bgpHealthCheckPort - defines the TCP port probed by the BGP health check
+	bgpHealthCheckPort = "bgp_healthcheckport"
+
+	// bgpHealthCheckInterval - defines the interval (in seconds) between probes
+	bgpHealthCheckInterval = "bgp_healthcheckinterval"
+
+	// lbEnable - defines if the load-balancer for the control plane is enabled
+	lbEnable = "lb_enable"
+
+	// lbPort - defines the port the load-balancer listens on
+	lbPort = "lb_port"
+
+	// lbForwardingMethod - defines the forwarding method of the load-balancer
+	lbForwardingMethod = "lb_fwdmethod"
+
+	// egressPodCidr - defines the pod cidr range to ignore for egress
+	egressPodCidr = "egress_podcidr"
+
+	// egressServiceCidr - defines the service cidr range to ignore for egress
+	egressServiceCidr = "egress_servicecidr"
+
+	// egressWithNftables - defines if nftables should be used over iptables
+	egressWithNftables = "egress_withnftables"
+
+	// egressDryRun - defines if egress only logs the rules it would apply
+	egressDryRun = "egress_dryrun"
+
+	// egressFWMark - defines the firewall mark used to identify egress traffic
+	egressFWMark = "egress_fwmark"
+
+	// enableNodeLabeling - defines if the node should be labeled as it becomes leader
+	enableNodeLabeling = "enable_node_labeling"
+
+	// nodeLabel - defines the label used to resolve the local node instead of the hostname
+	nodeLabel = "vip_nodelabel"
+
+	// enableEndpointSlices - defines if EndpointSlices are used instead of Endpoints
+	enableEndpointSlices = "enable_endpointslices"
+
+	// prometheusServer - defines the hostport used to expose Prometheus metrics
+	prometheusServer = "prometheus_server"
+)
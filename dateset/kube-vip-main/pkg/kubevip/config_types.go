@@ -0,0 +1,246 @@
+package kubevip
+
+import (
+	"github.com/kube-vip/kube-vip/pkg/bgp"
+)
+
+// Config defines all of the settings for the Kube-Vip Pod
+type Config struct {
+	// EnableARP, will use the ARP protocol to advertise the VIP address
+	EnableARP bool `yaml:"enableARP"`
+
+	// EnableBGP, will use the BGP protocol to advertise the VIP address
+	EnableBGP bool `yaml:"enableBGP"`
+
+	// EnableWireguard, will use wireguard to advertise the VIP address
+	EnableWireguard bool `yaml:"enableWireguard"`
+
+	// EnableRoutingTable, will use the routing table to advertise the VIP address
+	EnableRoutingTable bool `yaml:"enableRoutingTable"`
+
+	// CleanRoutingTable, will clean routes created by kube-vip that no longer
+	// have a backing service
+	CleanRoutingTable bool `yaml:"cleanRoutingTable"`
+
+	// EnableControlPlane, will enable the control plane functionality
+	EnableControlPlane bool `yaml:"enableControlPlane"`
+
+	// DetectControlPlane, will attempt to find the control plane from loopback (kubeadm)
+	DetectControlPlane bool `yaml:"detectControlPlane"`
+
+	// EnableServices, will enable the services functionality
+	EnableServices bool `yaml:"enableServices"`
+
+	// EnableServicesElection, will enable leaderElection per service
+	EnableServicesElection bool `yaml:"enableServicesElection"`
+
+	// EnableEndpointSlices, will use EndpointSlices instead of Endpoints to
+	// discover the backends of a service
+	EnableEndpointSlices bool `yaml:"enableEndpointSlices"`
+
+	// EnableNodeLabeling, will enable node labeling as it becomes leader
+	EnableNodeLabeling bool `yaml:"enableNodeLabeling"`
+
+	// NodeLabel, when set the node carrying <NodeLabel>=<hostname> is treated
+	// as the local node instead of relying on hostname comparison
+	NodeLabel string `yaml:"nodeLabel"`
+
+	// LoadBalancerClassOnly, will only advertise loadbalancer services with spec.loadBalancerClass=kube-vip.io/kube-vip-class
+	LoadBalancerClassOnly bool `yaml:"lbClassOnly"`
+
+	// LoadBalancerClassName, will only advertise loadbalancer services with spec.loadBalancerClass=<className>
+	LoadBalancerClassName string `yaml:"lbClassName"`
+
+	// EnableServiceSecurity, will enable the use of iptables to secure services
+	EnableServiceSecurity bool `yaml:"enableServiceSecurity"`
+
+	// DisableServiceUpdates, will disable updating the status of the watched services
+	DisableServiceUpdates bool `yaml:"disableServiceUpdates"`
+
+	// ArpBroadcastRate, defines how often kube-vip will update the network about updates to the network
+	ArpBroadcastRate int64 `yaml:"arpBroadcastRate"`
+
+	// Annotations will define if we're going to wait and lookup configuration from Kubernetes node annotations
+	Annotations string
+
+	// LeaderElectionType defines the backend to run the leader election: kubernetes or etcd. Defaults to kubernetes.
+	LeaderElectionType string `yaml:"leaderElectionType"`
+
+	// KubernetesLeaderElection defines the settings around Kubernetes leader election
+	KubernetesLeaderElection `yaml:",inline"`
+
+	// Etcd defines all the settings for the etcd client
+	Etcd Etcd
+
+	// Interface is the main network interface that the VIP should bind to
+	Interface string `yaml:"interface"`
+
+	// ServicesInterface is the network interface used for services of type LoadBalancer,
+	// when empty Interface is used
+	ServicesInterface string `yaml:"servicesInterface"`
+
+	// VIP is the Virtual IP address exposed for the cluster (TODO: deprecate)
+	VIP string `yaml:"vip"`
+
+	// VIPSubnet is the Subnet that is applied to the VIP
+	VIPSubnet string `yaml:"vipSubnet"`
+
+	// VIPCIDR is cidr range for the VIP (primarily needed for BGP)
+	VIPCIDR string `yaml:"vipCidr"`
+
+	// Address is the IP or DNS Name to use as a VirtualIP
+	Address string `yaml:"address"`
+
+	// Listen port for the VirtualIP
+	Port int `yaml:"port"`
+
+	// SingleNode will start the cluster as a single Node (Raft disabled)
+	SingleNode bool `yaml:"singleNode"`
+
+	// Namespace will define which namespace the control plane pods will run in
+	Namespace string `yaml:"namespace"`
+
+	// ServiceNamespace will define the namespace the services are watched in
+	ServiceNamespace string `yaml:"serviceNamespace"`
+
+	// ServicesLeaseName will set the lease name for services leader in arp mode
+	ServicesLeaseName string `yaml:"servicesLeaseName"`
+
+	// use DDNS to allocate IP when Address is set to a DNS Name
+	DDNS bool `yaml:"ddns"`
+
+	// DDNSProviderEndpoint is the update URL of an external dynamic DNS
+	// provider (dyndns2-style API); when set the VIP address is pushed there
+	// whenever this node takes the VIP over
+	DDNSProviderEndpoint string `yaml:"ddnsProviderEndpoint"`
+
+	// DDNSProviderUsername and DDNSProviderPassword authenticate against the
+	// dynamic DNS provider endpoint
+	DDNSProviderUsername string `yaml:"ddnsProviderUsername"`
+	DDNSProviderPassword string `yaml:"ddnsProviderPassword"`
+
+	// RoutingTableID assigns the ID of table that will be used for routing advertisement
+	RoutingTableID int `yaml:"routingTableID"`
+
+	// RoutingTableType defines what type of route will be used for routing advertisement
+	RoutingTableType int `yaml:"routingTableType"`
+
+	// RoutingProtocol defines what protocol value will be used for routing advertisement
+	RoutingProtocol int `yaml:"routingProtocol"`
+
+	// DNSMode defines how the address for a DNS name is resolved (first, ipv4, ipv6, dual)
+	DNSMode string `yaml:"dnsMode"`
+
+	// BGP Configuration
+	BGPConfig     bgp.Config
+	BGPPeerConfig bgp.Peer
+	BGPPeers      []string
+
+	// EnableBGPHealthCheck gates the BGP advertisement of a VIP behind a local
+	// TCP readiness probe, so an unhealthy node never attracts traffic
+	EnableBGPHealthCheck bool `yaml:"enableBGPHealthCheck"`
+
+	// BGPHealthCheckPort is the TCP port probed by the BGP health check,
+	// when zero Port is probed instead
+	BGPHealthCheckPort int `yaml:"bgpHealthCheckPort"`
+
+	// BGPHealthCheckInterval is the interval (in seconds) between BGP health
+	// check probes, defaulting to 5 seconds when zero
+	BGPHealthCheckInterval int `yaml:"bgpHealthCheckInterval"`
+
+	// EnableMetal, will use the metal API to update the EIP <-> VIP (if BGP is enabled then BGP will be used)
+	EnableMetal bool `yaml:"enableMetal"`
+
+	// MetalAPIKey, is the API token used to authenticate to the API
+	MetalAPIKey string
+
+	// MetalProject, is the name of a particular defined project
+	MetalProject string
+
+	// MetalProjectID, is the ID of a particular defined project
+	MetalProjectID string
+
+	// ProviderConfig, is the path to a provider configuration file
+	ProviderConfig string
+
+	// EnableLoadBalancer, will enable the IPVS load balancer for the control plane
+	EnableLoadBalancer bool `yaml:"enableLoadBalancer"`
+
+	// LoadBalancerPort, is the port that the IPVS load balancer will listen on
+	LoadBalancerPort int `yaml:"lbPort"`
+
+	// LoadBalancerForwardingMethod, is the forwarding method of the IPVS load balancer
+	LoadBalancerForwardingMethod string `yaml:"lbForwardingMethod"`
+
+	// LoadBalancers are the various services we can load balance over
+	LoadBalancers []LoadBalancer `yaml:"loadBalancers,omitempty"`
+
+	// The hostport used to expose Prometheus metrics over
+	PrometheusHTTPServer string `yaml:"prometheusHTTPServer,omitempty"`
+
+	// Egress configuration
+
+	// EgressPodCidr, this contains the pod cidr range to ignore Egress
+	EgressPodCidr string
+
+	// EgressServiceCidr, this contains the service cidr range to ignore
+	EgressServiceCidr string
+
+	// EgressWithNftables, this will use iptables-nftables over iptables
+	EgressWithNftables bool
+
+	// EgressDryRun, only logs the iptables rules egress would apply instead
+	// of changing anything, for auditing rules on a production node
+	EgressDryRun bool
+
+	// EgressFWMark, the firewall mark (value/mask) used to identify egress
+	// traffic; when empty the default 64/64 is used
+	EgressFWMark string
+}
+
+// KubernetesLeaderElection defines the settings around Kubernetes KubernetesLeaderElection
+type KubernetesLeaderElection struct {
+	// EnableLeaderElection will use the Kubernetes leader election algorithm
+	EnableLeaderElection bool `yaml:"enableLeaderElection"`
+
+	// LeaseName is the name of the lease used for the leader election
+	LeaseName string `yaml:"leaseName"`
+
+	// Lease Duration - length of time a lease can be held for
+	LeaseDuration int
+
+	// RenewDeadline - length of time a host can attempt to renew its lease
+	RenewDeadline int
+
+	// RetryPeriod - Number of times the host will retry to hold a lease
+	RetryPeriod int
+
+	// LeaseAnnotations - annotations which will be given to the lease object
+	LeaseAnnotations map[string]string
+}
+
+// Etcd defines all the settings for the etcd client
+type Etcd struct {
+	CAFile         string
+	ClientCertFile string
+	ClientKeyFile  string
+	Endpoints      []string
+}
+
+// LoadBalancer contains the configuration of a load balancing instance
+type LoadBalancer struct {
+	// Name of a LoadBalancer
+	Name string `yaml:"name"`
+
+	// Type of LoadBalancer, either TCP of HTTP(s)
+	Type string `yaml:"type"`
+
+	// Listening frontend port of this LoadBalancer instance
+	Port int `yaml:"port"`
+
+	// BindToVip will bind the load balancer port to the VIP itself
+	BindToVip bool `yaml:"bindToVip"`
+
+	// Forwarding method of LoadBalancer, either Local, Tunnel, DirectRoute or Bypass
+	ForwardingMethod string `yaml:"forwardingMethod"`
+}
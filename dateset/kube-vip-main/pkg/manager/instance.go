@@ -3,6 +3,7 @@ package manager
 import (
 	"fmt"
 	"net"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
@@ -71,6 +72,9 @@ func NewInstance(svc *v1.Service, config *kubevip.Config) (*Instance, error) {
 			DNSMode:                config.DNSMode,
 			DisableServiceUpdates:  config.DisableServiceUpdates,
 			EnableServicesElection: config.EnableServicesElection,
+			// May be overridden per-service through the
+			// kube-vip.io/forwarding-method annotation below
+			LoadBalancerForwardingMethod: config.LoadBalancerForwardingMethod,
 			KubernetesLeaderElection: kubevip.KubernetesLeaderElection{
 				EnableLeaderElection: config.EnableLeaderElection,
 			},
@@ -92,6 +96,18 @@ func NewInstance(svc *v1.Service, config *kubevip.Config) (*Instance, error) {
 		instance.dhcpInterfaceHwaddr = svc.Annotations[hwAddrKey]
 		instance.dhcpInterfaceIP = svc.Annotations[requestedIP]
 		instance.dhcpHostname = svc.Annotations[loadbalancerHostname]
+
+		// A service may select its own IPVS forwarding method, falling back
+		// to the global configuration when the annotation is unset
+		if method := svc.Annotations[forwardingMethod]; method != "" {
+			if !validForwardingMethod(method) {
+				return nil, fmt.Errorf("service %s/%s: invalid %s annotation %q (must be one of local, tunnel, masquerade, directroute, bypass)",
+					svc.Namespace, svc.Name, forwardingMethod, method)
+			}
+			for _, vip := range newVips {
+				vip.LoadBalancerForwardingMethod = method
+			}
+		}
 	}
 
 	// Generate Load Balancer config
@@ -125,6 +141,16 @@ func NewInstance(svc *v1.Service, config *kubevip.Config) (*Instance, error) {
 			instance.dhcpInterfaceIP = ip
 		}
 	}
+	// A service may request a lower route MTU, e.g. when the VIP route
+	// traverses links with a smaller MTU
+	routeMTU := 0
+	if mtu := svc.Annotations[serviceRouteMTU]; mtu != "" {
+		routeMTU, err = strconv.Atoi(mtu)
+		if err != nil || routeMTU < 0 {
+			return nil, fmt.Errorf("service %s/%s: invalid %s annotation %q", svc.Namespace, svc.Name, serviceRouteMTU, mtu)
+		}
+	}
+
 	for _, vipConfig := range instance.vipConfigs {
 		c, err := cluster.InitCluster(vipConfig, false)
 		if err != nil {
@@ -134,6 +160,9 @@ func NewInstance(svc *v1.Service, config *kubevip.Config) (*Instance, error) {
 
 		for i := range c.Network {
 			c.Network[i].SetServicePorts(svc)
+			if routeMTU > 0 {
+				c.Network[i].SetRouteMTU(routeMTU)
+			}
 		}
 
 		instance.clusters = append(instance.clusters, c)
@@ -222,3 +251,13 @@ func (i *Instance) startDHCP() error {
 
 	return nil
 }
+
+// validForwardingMethod reports whether method is one of the IPVS forwarding
+// methods accepted by loadbalancer.NewIPVSLB.
+func validForwardingMethod(method string) bool {
+	switch method {
+	case "local", "tunnel", "masquerade", "directroute", "bypass":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package manager
+
+import "testing"
+
+func Test_validForwardingMethod(t *testing.T) {
+	for _, method := range []string{"local", "tunnel", "masquerade", "directroute", "bypass"} {
+		if !validForwardingMethod(method) {
+			t.Errorf("validForwardingMethod(%q) = false, want true", method)
+		}
+	}
+	for _, method := range []string{"", "nat", "Masquerade", "direct"} {
+		if validForwardingMethod(method) {
+			t.Errorf("validForwardingMethod(%q) = true, want false", method)
+		}
+	}
+}
@@ -54,6 +54,14 @@ type Manager struct {
 	// 1 means "ESTABLISHED", 0 means "NOT ESTABLISHED"
 	bgpSessionInfoGauge *prometheus.GaugeVec
 
+	// This is a prometheus histogram tracking the time taken between
+	// observing a ready local endpoint and completing its route/BGP advertisement
+	endpointAdvertiseTime prometheus.Histogram
+
+	// This is a prometheus counter tracking how often a service restarts its
+	// leader election because its active endpoint disappeared
+	electionFlapCount *prometheus.CounterVec
+
 	// This mutex is to protect calls from various goroutines
 	mutex sync.Mutex
 }
@@ -135,6 +143,19 @@ func New(configMap string, config *kubevip.Config) (*Manager, error) {
 			Name:      "bgp_session_info",
 			Help:      "Display state of session by setting metric for label value with current state to 1",
 		}, []string{"state", "peer"}),
+		endpointAdvertiseTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "kube_vip",
+			Subsystem: "manager",
+			Name:      "endpoint_advertise_duration_seconds",
+			Help:      "Time taken from observing a ready local endpoint to completing its route/BGP advertisement",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		electionFlapCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "kube_vip",
+			Subsystem: "manager",
+			Name:      "service_election_flap_count",
+			Help:      "Count how often a service restarted its leader election because the active endpoint disappeared",
+		}, []string{"namespace", "name"}),
 	}, nil
 }
 
@@ -103,7 +103,7 @@ func (sm *Manager) startARP() error {
 
 	// This will tidy any dangling kube-vip iptables rules
 	if os.Getenv("EGRESS_CLEAN") != "" {
-		i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, sm.config.ServiceNamespace, iptables.ProtocolIPv4)
+		i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, sm.config.ServiceNamespace, iptables.ProtocolIPv4, sm.config.EgressDryRun, sm.config.EgressFWMark)
 		if err != nil {
 			log.Warnf("(egress) Unable to clean any dangling egress rules [%v]", err)
 			log.Warn("(egress) Can be ignored in non iptables release of kube-vip")
@@ -119,6 +119,14 @@ func (sm *Manager) startARP() error {
 	// Start a services watcher (all kube-vip pods will watch services), upon a new service
 	// a lock based upon that service is created that they will all leaderElection on
 	if sm.config.EnableServicesElection {
+		// Watch this node for the drain label, so that a drain can hand the
+		// service leases over to another node before this one goes away
+		go func() {
+			if err := sm.watchNodeForDrainLabel(id); err != nil {
+				log.Errorf("(node drain) %v", err)
+			}
+		}()
+
 		log.Infof("beginning watching services, leaderelection will happen for every service")
 		err = sm.startServicesWatchForLeaderElection(ctx)
 		if err != nil {
@@ -6,14 +6,22 @@ import (
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 )
 
 const (
 	nodeLabelIndex    = "kube-vip.io/has-ip"
 	nodeLabelJSONPath = `kube-vip.io~1has-ip`
+
+	// nodeDrainLabelIndex marks a node that is being drained; kube-vip stops
+	// participating in service leader elections on a node carrying it
+	nodeDrainLabelIndex = "kube-vip.io/drain"
 )
 
 type patchStringLabel struct {
@@ -71,3 +79,106 @@ func applyPatchLabels(ctx context.Context, clientSet *kubernetes.Clientset,
 	}
 	log.Debugf("updated node %s labels: %+v", name, node.Labels)
 }
+
+// processNodeDrainEvent checks a node update for the drain label and, if present,
+// cancels the service leader elections this node is participating in so that
+// another node can take the leases over.
+func (sm *Manager) processNodeDrainEvent(node *v1.Node) {
+	if !nodeHasDrainLabel(node) {
+		return
+	}
+	log.Infof("node [%s] is labeled [%s], stopping service elections", node.Name, nodeDrainLabelIndex)
+	sm.stopServiceElections()
+}
+
+// nodeHasDrainLabel returns true when the node carries the kube-vip drain label
+func nodeHasDrainLabel(node *v1.Node) bool {
+	value, ok := node.Labels[nodeDrainLabelIndex]
+	return ok && value != "false"
+}
+
+// stopServiceElections cancels every active service leader election on this node
+func (sm *Manager) stopServiceElections() {
+	// take the entries under the lock, as the services watcher writes the
+	// same maps from its own goroutine, then cancel outside of it
+	sm.mutex.Lock()
+	cancels := make(map[string]func(), len(activeServiceLoadBalancerCancel))
+	for uid, cancel := range activeServiceLoadBalancerCancel {
+		cancels[uid] = cancel
+		delete(activeServiceLoadBalancerCancel, uid)
+		delete(activeServiceLoadBalancer, uid)
+	}
+	sm.mutex.Unlock()
+	for uid, cancel := range cancels {
+		log.Infof("(svc election) cancelling election for service UID [%s]", uid)
+		cancel()
+	}
+}
+
+// watchNodeForDrainLabel watches this node and cancels the node's service leader
+// elections once the drain label appears, so a drain can move VIPs elsewhere
+// before the node goes away.
+func (sm *Manager) watchNodeForDrainLabel(nodeName string) error {
+	listOptions := metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("metadata.name=%s", nodeName),
+	}
+
+	nodeList, err := sm.clientSet.CoreV1().Nodes().List(context.Background(), listOptions)
+	if err != nil {
+		return err
+	}
+	if len(nodeList.Items) == 0 {
+		return fmt.Errorf("unable to find node [%s]", nodeName)
+	}
+	node := nodeList.Items[0]
+	sm.processNodeDrainEvent(&node)
+
+	// Use a restartable watcher, as this should help in the event of etcd or timeout issues
+	rw, err := watchtools.NewRetryWatcher(node.ResourceVersion, &cache.ListWatch{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return sm.clientSet.CoreV1().Nodes().Watch(context.Background(), listOptions)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating node drain watcher: %s", err.Error())
+	}
+
+	exitFunction := make(chan struct{})
+	go func() {
+		select {
+		case <-sm.shutdownChan:
+			log.Debug("[node drain] shutdown called")
+			// Stop the retry watcher
+			rw.Stop()
+			return
+		case <-exitFunction:
+			log.Debug("[node drain] function ending")
+			// Stop the retry watcher
+			rw.Stop()
+			return
+		}
+	}()
+
+	ch := rw.ResultChan()
+
+	for event := range ch {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			node, ok := event.Object.(*v1.Node)
+			if !ok {
+				return fmt.Errorf("unable to parse Kubernetes Node from drain watcher")
+			}
+			sm.processNodeDrainEvent(node)
+		case watch.Deleted:
+			node, ok := event.Object.(*v1.Node)
+			if !ok {
+				return fmt.Errorf("unable to parse Kubernetes Node from Kubernetes watcher")
+			}
+			log.Infof("Node [%s] has been deleted", node.Name)
+		default:
+		}
+	}
+	close(exitFunction)
+	log.Infoln("Exiting node drain watcher")
+	return nil
+}
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProcessNodeDrainEvent(t *testing.T) {
+	activeServiceLoadBalancer = make(map[string]context.Context)
+	activeServiceLoadBalancerCancel = make(map[string]func())
+
+	cancelled := make(map[string]bool)
+	for _, uid := range []string{"uid1", "uid2"} {
+		uid := uid
+		activeServiceLoadBalancer[uid] = context.TODO()
+		activeServiceLoadBalancerCancel[uid] = func() {
+			cancelled[uid] = true
+		}
+	}
+
+	sm := &Manager{}
+
+	// A node without the drain label must leave elections untouched
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{}},
+	}
+	sm.processNodeDrainEvent(node)
+	assert.Empty(t, cancelled, "no election should be cancelled without the drain label")
+	assert.Len(t, activeServiceLoadBalancerCancel, 2)
+
+	// Labeling the node must cancel all of its active elections
+	node.Labels[nodeDrainLabelIndex] = "true"
+	sm.processNodeDrainEvent(node)
+	assert.True(t, cancelled["uid1"], "election for uid1 should be cancelled")
+	assert.True(t, cancelled["uid2"], "election for uid2 should be cancelled")
+	assert.Empty(t, activeServiceLoadBalancerCancel)
+	assert.Empty(t, activeServiceLoadBalancer)
+}
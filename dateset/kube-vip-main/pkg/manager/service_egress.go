@@ -91,7 +91,7 @@ func (sm *Manager) configureEgress(vipIP, podIP, destinationPorts, namespace str
 		protocol = iptables.ProtocolIPv6
 	}
 
-	i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, namespace, protocol)
+	i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, namespace, protocol, sm.config.EgressDryRun, sm.config.EgressFWMark)
 	if err != nil {
 		return fmt.Errorf("error Creating iptables client [%s]", err)
 	}
@@ -196,7 +196,7 @@ func (sm *Manager) TeardownEgress(podIP, vipIP, destinationPorts, namespace stri
 		protocol = iptables.ProtocolIPv6
 	}
 
-	i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, namespace, protocol)
+	i, err := vip.CreateIptablesClient(sm.config.EgressWithNftables, namespace, protocol, sm.config.EgressDryRun, sm.config.EgressFWMark)
 	if err != nil {
 		return fmt.Errorf("error Creating iptables client [%s]", err)
 	}
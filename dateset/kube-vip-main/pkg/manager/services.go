@@ -31,6 +31,8 @@ const (
 	flushContrack            = "kube-vip.io/flush-conntrack"
 	loadbalancerIPAnnotation = "kube-vip.io/loadbalancerIPs"
 	loadbalancerHostname     = "kube-vip.io/loadbalancerHostname"
+	forwardingMethod         = "kube-vip.io/forwarding-method"
+	serviceRouteMTU          = "kube-vip.io/route-mtu"
 )
 
 func (sm *Manager) syncServices(_ context.Context, svc *v1.Service, wg *sync.WaitGroup) error {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/kube-vip/kube-vip/pkg/kubevip"
 	log "github.com/sirupsen/logrus"
@@ -27,6 +28,7 @@ type epProvider interface {
 		*v1.Service) (*watchtools.RetryWatcher, error)
 	getAllEndpoints() ([]string, error)
 	getLocalEndpoints(string, *kubevip.Config) ([]string, error)
+	getLocalEndpointsByZone(string, string, *kubevip.Config) ([]string, error)
 	getLabel() string
 	updateServiceAnnotation(string, string, *v1.Service, *Manager) error
 	loadObject(runtime.Object, context.CancelFunc) error
@@ -76,7 +78,23 @@ func (ep *endpointsProvider) getAllEndpoints() ([]string, error) {
 		}
 	}
 
-	return result, nil
+	return uniqueAddresses(result), nil
+}
+
+// uniqueAddresses removes duplicate IPs while keeping the original order. The
+// same address can show up more than once across subsets, or via mirrored
+// EndpointSlices, which would otherwise be advertised (or counted) twice.
+func uniqueAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	result := []string{}
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		result = append(result, address)
+	}
+	return result
 }
 
 func (ep *endpointsProvider) getLocalEndpoints(id string, config *kubevip.Config) ([]string, error) {
@@ -120,7 +138,13 @@ func (ep *endpointsProvider) getLocalEndpoints(id string, config *kubevip.Config
 			}
 		}
 	}
-	return localEndpoints, nil
+	return uniqueAddresses(localEndpoints), nil
+}
+
+// getLocalEndpointsByZone falls back to the node-local selection; the core
+// Endpoints API carries no topology information to match a zone against
+func (ep *endpointsProvider) getLocalEndpointsByZone(id, _ string, config *kubevip.Config) ([]string, error) {
+	return ep.getLocalEndpoints(id, config)
 }
 
 func (ep *endpointsProvider) updateServiceAnnotation(endpoint string, _ string, service *v1.Service, sm *Manager) error {
@@ -205,6 +229,31 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 
 	ch := rw.ResultChan()
 
+	// When a node label is configured, resolve the node name this host maps
+	// to once, so endpoints keep matching when kubelet hostname != node name
+	if nodeName := sm.nodeNameByLabel(id); nodeName != "" {
+		id = nodeName
+	}
+
+	// Resolve the node's topology zone once, so endpoint selection can prefer
+	// same-zone endpoints for services with a Local traffic policy
+	var zone string
+	if service.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal {
+		zone = sm.nodeZone(id)
+	}
+
+	// Coalesce annotation writes so a busy service doesn't hammer the
+	// apiserver with a Get+Update for every endpoint change
+	debounce := newAnnotationDebouncer(defaultAnnotationDebounce, func(endpoint, endpointIPv6 string) {
+		if err := provider.updateServiceAnnotation(endpoint, endpointIPv6, service, sm); err != nil {
+			log.Errorf("[%s] error updating annotations for %s/%s: %v", provider.getLabel(), service.Namespace, service.Name, err)
+		}
+	})
+
+	// One structured log entry per election state transition, so the history
+	// of a VIP can be traced from the log alone
+	stateLog := newElectionStateLogger(provider.getLabel(), service)
+
 	var lastKnownGoodEndpoint string
 	for event := range ch {
 		activeEndpointAnnotation := activeEndpoint
@@ -229,7 +278,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 					return fmt.Errorf("[%s] error getting all endpoints: %w", provider.getLabel(), err)
 				}
 			} else {
-				if endpoints, err = provider.getLocalEndpoints(id, sm.config); err != nil {
+				if endpoints, err = provider.getLocalEndpointsByZone(id, zone, sm.config); err != nil {
 					return fmt.Errorf("[%s] error getting local endpoints: %w", provider.getLabel(), err)
 				}
 			}
@@ -242,6 +291,9 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 
 			// Check that we have local endpoints
 			if len(endpoints) != 0 {
+				// Record when the endpoint was observed, so the time until the
+				// advertisement completes can be measured below
+				endpointObserved := time.Now()
 				// if we haven't populated one, then do so
 				if lastKnownGoodEndpoint != "" {
 
@@ -255,6 +307,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 					}
 					// If the last endpoint no longer exists, we cancel our leader Election
 					if !stillExists && leaderElectionActive {
+						sm.electionFlapCount.WithLabelValues(service.Namespace, service.Name).Inc()
 						if sm.config.EnableServicesElection || sm.config.EnableLeaderElection {
 							log.Warnf("[%s] existing [%s] has been removed, restarting leaderElection", provider.getLabel(), lastKnownGoodEndpoint)
 							// Stop the existing leaderElection
@@ -273,6 +326,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 				// Set the service accordingly
 				if service.Annotations[egress] == "true" {
 					service.Annotations[activeEndpointAnnotation] = lastKnownGoodEndpoint
+					debounce.update(ctx, service.Annotations[activeEndpoint], service.Annotations[activeEndpointIPv6])
 				}
 
 				if !leaderElectionActive && sm.config.EnableServicesElection {
@@ -328,6 +382,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 											provider.getLabel(), cluster.Network[i].IP(), service.Namespace, service.Name, cluster.Network[i].Interface(), sm.config.RoutingTableID)
 										configuredLocalRoutes.Store(string(service.UID), true)
 										leaderElectionActive = true
+										sm.observeAdvertisement(endpointObserved)
 									}
 								}
 							}
@@ -349,6 +404,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 											provider.getLabel(), address, service.Namespace, service.Name)
 										configuredLocalRoutes.Store(string(service.UID), true)
 										leaderElectionActive = true
+										sm.observeAdvertisement(endpointObserved)
 									}
 								}
 							}
@@ -398,8 +454,15 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 			}
 			log.Debugf("[%s watcher] service %s/%s: local endpoint(s) [%d], known good [%s], active election [%t]",
 				provider.getLabel(), service.Namespace, service.Name, len(endpoints), lastKnownGoodEndpoint, leaderElectionActive)
+			stateLog.transition(lastKnownGoodEndpoint, leaderElectionActive)
 
 		case watch.Deleted:
+			stateLog.transition("", false)
+			// The endpoints going away ends any election that was running for them
+			if leaderElectionActive {
+				sm.electionFlapCount.WithLabelValues(service.Namespace, service.Name).Inc()
+			}
+
 			// When no-leader-elecition mode
 			if !sm.config.EnableServicesElection && !sm.config.EnableLeaderElection {
 				// find all existing local endpoints
@@ -410,7 +473,7 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 						return fmt.Errorf("[%s] error getting all endpoints: %w", provider.getLabel(), err)
 					}
 				} else {
-					if endpoints, err = provider.getLocalEndpoints(id, sm.config); err != nil {
+					if endpoints, err = provider.getLocalEndpointsByZone(id, zone, sm.config); err != nil {
 						return fmt.Errorf("[%s] error getting all endpoints: %w", provider.getLabel(), err)
 					}
 				}
@@ -445,6 +508,15 @@ func (sm *Manager) watchEndpoint(ctx context.Context, id string, service *v1.Ser
 	return nil //nolint:govet
 }
 
+// observeAdvertisement records the time taken from observing a ready local
+// endpoint to completing its route/BGP advertisement
+func (sm *Manager) observeAdvertisement(endpointObserved time.Time) {
+	if sm.endpointAdvertiseTime == nil {
+		return
+	}
+	sm.endpointAdvertiseTime.Observe(time.Since(endpointObserved).Seconds())
+}
+
 func (sm *Manager) clearRoutes(service *v1.Service) []error {
 	errs := []error{}
 	if instance := sm.findServiceInstance(service); instance != nil {
@@ -491,3 +563,136 @@ func getShortname(hostname string) (string, error) {
 	}
 	return "", fmt.Errorf("unable to find shortname from %s", hostname)
 }
+
+// nodeNameByLabel resolves the Kubernetes node name of this host through the
+// configured node label: the node carrying <label>=<id> is considered local.
+// It returns an empty string when no label is configured or the lookup is not
+// conclusive, in which case the usual hostname comparison applies.
+func (sm *Manager) nodeNameByLabel(id string) string {
+	if sm.config.NodeLabel == "" {
+		return ""
+	}
+	nodes, err := sm.clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: sm.config.NodeLabel + "=" + id,
+	})
+	if err != nil {
+		log.Errorf("[endpoint] unable to list nodes with label [%s=%s]: %v", sm.config.NodeLabel, id, err)
+		return ""
+	}
+	if len(nodes.Items) != 1 {
+		log.Warnf("[endpoint] expected one node with label [%s=%s], found %d; falling back to hostname matching", sm.config.NodeLabel, id, len(nodes.Items))
+		return ""
+	}
+	return nodes.Items[0].Name
+}
+
+// nodeZone returns the topology zone label of the named node, used to prefer
+// same-zone endpoints; an empty string disables the zone preference
+func (sm *Manager) nodeZone(id string) string {
+	node, err := sm.clientSet.CoreV1().Nodes().Get(context.TODO(), id, metav1.GetOptions{})
+	if err != nil {
+		log.Debugf("[endpoint] unable to get node [%s] for the zone lookup: %v", id, err)
+		return ""
+	}
+	return node.Labels["topology.kubernetes.io/zone"]
+}
+
+// electionStateLogger tracks the election state of a watched service and emits
+// one structured log entry per transition (endpoint changed, election
+// started/stopped), which makes tracing why a VIP moved a matter of following
+// a single log key instead of scattered Infof/Warnf lines
+type electionStateLogger struct {
+	label   string
+	service string
+	// emit replaces the default structured log call, so tests can capture
+	// the transitions
+	emit func(fields log.Fields)
+
+	endpoint       string
+	electionActive bool
+}
+
+func newElectionStateLogger(label string, service *v1.Service) *electionStateLogger {
+	return &electionStateLogger{
+		label:   label,
+		service: service.Namespace + "/" + service.Name,
+	}
+}
+
+// transition records the new state, logging it when it differs from the
+// previous one; repeated identical states stay silent
+func (e *electionStateLogger) transition(endpoint string, electionActive bool) {
+	if endpoint == e.endpoint && electionActive == e.electionActive {
+		return
+	}
+	fields := log.Fields{
+		"provider":       e.label,
+		"service":        e.service,
+		"oldEndpoint":    e.endpoint,
+		"newEndpoint":    endpoint,
+		"electionActive": electionActive,
+	}
+	if e.emit != nil {
+		e.emit(fields)
+	} else {
+		log.WithFields(fields).Info("election state transition")
+	}
+	e.endpoint = endpoint
+	e.electionActive = electionActive
+}
+
+// defaultAnnotationDebounce is the minimum interval between two annotation
+// writes for the same service
+const defaultAnnotationDebounce = time.Second
+
+// annotationDebouncer coalesces rapid annotation updates so at most one write
+// per interval reaches the apiserver, while guaranteeing the most recent
+// values are the ones written
+type annotationDebouncer struct {
+	interval time.Duration
+	write    func(endpoint, endpointIPv6 string)
+
+	mu           sync.Mutex
+	pending      bool
+	endpoint     string
+	endpointIPv6 string
+}
+
+func newAnnotationDebouncer(interval time.Duration, write func(endpoint, endpointIPv6 string)) *annotationDebouncer {
+	if interval <= 0 {
+		interval = defaultAnnotationDebounce
+	}
+	return &annotationDebouncer{
+		interval: interval,
+		write:    write,
+	}
+}
+
+// update records the latest endpoint values and schedules a single write;
+// calls arriving while a write is pending only replace the recorded values.
+// A cancelled context drops the scheduled write.
+func (d *annotationDebouncer) update(ctx context.Context, endpoint, endpointIPv6 string) {
+	d.mu.Lock()
+	d.endpoint, d.endpointIPv6 = endpoint, endpointIPv6
+	if d.pending {
+		d.mu.Unlock()
+		return
+	}
+	d.pending = true
+	d.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(d.interval):
+		}
+		d.mu.Lock()
+		endpoint, endpointIPv6 := d.endpoint, d.endpointIPv6
+		d.pending = false
+		d.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		d.write(endpoint, endpointIPv6)
+	}()
+}
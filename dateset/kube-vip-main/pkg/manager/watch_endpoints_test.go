@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_annotationDebouncer(t *testing.T) {
+	var mu sync.Mutex
+	var writes []string
+	d := newAnnotationDebouncer(20*time.Millisecond, func(endpoint, _ string) {
+		mu.Lock()
+		writes = append(writes, endpoint)
+		mu.Unlock()
+	})
+
+	// Rapid successive updates collapse into a single write of the final value
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, endpoint := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		d.update(ctx, endpoint, "")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	if len(writes) != 1 || writes[0] != "10.0.0.3" {
+		t.Errorf("expected a single write of the final endpoint, got %v", writes)
+	}
+	mu.Unlock()
+
+	// A cancelled context drops the scheduled write
+	cancelledCtx, cancelNow := context.WithCancel(context.Background())
+	d.update(cancelledCtx, "10.0.0.4", "")
+	cancelNow()
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	if len(writes) != 1 {
+		t.Errorf("expected no write after cancellation, got %v", writes)
+	}
+	mu.Unlock()
+}
+
+func Test_electionStateLogger(t *testing.T) {
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	var transitions []log.Fields
+	e := newElectionStateLogger("endpoint", service)
+	e.emit = func(fields log.Fields) {
+		transitions = append(transitions, fields)
+	}
+
+	// Initial endpoint, election start, endpoint move, teardown
+	e.transition("10.0.0.1", false)
+	e.transition("10.0.0.1", true)
+	e.transition("10.0.0.1", true) // no change, stays silent
+	e.transition("10.0.0.2", true)
+	e.transition("", false)
+
+	if len(transitions) != 4 {
+		t.Fatalf("expected 4 transitions, got %d: %v", len(transitions), transitions)
+	}
+	if transitions[0]["service"] != "default/web" {
+		t.Errorf("expected the namespaced service name, got %v", transitions[0]["service"])
+	}
+	if transitions[2]["oldEndpoint"] != "10.0.0.1" || transitions[2]["newEndpoint"] != "10.0.0.2" {
+		t.Errorf("expected the endpoint move to carry old and new endpoint, got %v", transitions[2])
+	}
+	if transitions[3]["electionActive"] != false {
+		t.Errorf("expected the teardown to report an inactive election, got %v", transitions[3])
+	}
+}
@@ -52,12 +52,31 @@ func (ep *endpointslicesProvider) loadObject(endpoints runtime.Object, cancel co
 	return nil
 }
 
+// servingEndpoints returns the endpoints to consider for selection. Endpoints
+// that are terminating (the pod is shutting down) are dropped as long as at
+// least one non-terminating endpoint remains; when only terminating endpoints
+// are left they are kept, so traffic isn't blackholed during a rollout.
+func (ep *endpointslicesProvider) servingEndpoints() []discoveryv1.Endpoint {
+	remaining := make([]discoveryv1.Endpoint, 0, len(ep.endpoints.Endpoints))
+	for i := range ep.endpoints.Endpoints {
+		if ep.endpoints.Endpoints[i].Conditions.Terminating != nil && *ep.endpoints.Endpoints[i].Conditions.Terminating {
+			log.Debugf("[%s] skipping terminating endpoint - addresses: %v", ep.label, ep.endpoints.Endpoints[i].Addresses)
+			continue
+		}
+		remaining = append(remaining, ep.endpoints.Endpoints[i])
+	}
+	if len(remaining) == 0 {
+		return ep.endpoints.Endpoints
+	}
+	return remaining
+}
+
 func (ep *endpointslicesProvider) getAllEndpoints() ([]string, error) {
 	result := []string{}
-	for _, ep := range ep.endpoints.Endpoints {
-		result = append(result, ep.Addresses...)
+	for _, endpoint := range ep.servingEndpoints() {
+		result = append(result, endpoint.Addresses...)
 	}
-	return result, nil
+	return uniqueAddresses(result), nil
 }
 
 func (ep *endpointslicesProvider) getLocalEndpoints(id string, config *kubevip.Config) ([]string, error) {
@@ -71,39 +90,66 @@ func (ep *endpointslicesProvider) getLocalEndpoints(id string, config *kubevip.C
 	}
 
 	var localEndpoints []string
-	for i := range ep.endpoints.Endpoints {
-		for j := range ep.endpoints.Endpoints[i].Addresses {
+	endpoints := ep.servingEndpoints()
+	for i := range endpoints {
+		for j := range endpoints[i].Addresses {
 			// 1. Compare the hostname on the endpoint to the hostname
 			// 2. Compare the nodename on the endpoint to the hostname
 			// 3. Drop the FQDN to a shortname and compare to the nodename on the endpoint
 
 			// 1. Compare the Hostname first (should be FQDN)
-			log.Debugf("[%s] processing endpoint [%s]", ep.label, ep.endpoints.Endpoints[i].Addresses[j])
-			if ep.endpoints.Endpoints[i].Hostname != nil && id == *ep.endpoints.Endpoints[i].Hostname {
-				if *ep.endpoints.Endpoints[i].Conditions.Serving {
-					log.Debugf("[%s] found endpoint - address: %s, hostname: %s", ep.label, ep.endpoints.Endpoints[i].Addresses[j], *ep.endpoints.Endpoints[i].Hostname)
-					localEndpoints = append(localEndpoints, ep.endpoints.Endpoints[i].Addresses[j])
+			log.Debugf("[%s] processing endpoint [%s]", ep.label, endpoints[i].Addresses[j])
+			if endpoints[i].Hostname != nil && id == *endpoints[i].Hostname {
+				if *endpoints[i].Conditions.Serving {
+					log.Debugf("[%s] found endpoint - address: %s, hostname: %s", ep.label, endpoints[i].Addresses[j], *endpoints[i].Hostname)
+					localEndpoints = append(localEndpoints, endpoints[i].Addresses[j])
 				}
 			} else {
 				// 2. Compare the Nodename (from testing could be FQDN or short)
-				if ep.endpoints.Endpoints[i].NodeName != nil {
-					if id == *ep.endpoints.Endpoints[i].NodeName && *ep.endpoints.Endpoints[i].Conditions.Serving {
-						if ep.endpoints.Endpoints[i].Hostname != nil {
-							log.Debugf("[%s] found endpoint - address: %s, hostname: %s, node: %s", ep.label, ep.endpoints.Endpoints[i].Addresses[j], *ep.endpoints.Endpoints[i].Hostname, *ep.endpoints.Endpoints[i].NodeName)
+				if endpoints[i].NodeName != nil {
+					if id == *endpoints[i].NodeName && *endpoints[i].Conditions.Serving {
+						if endpoints[i].Hostname != nil {
+							log.Debugf("[%s] found endpoint - address: %s, hostname: %s, node: %s", ep.label, endpoints[i].Addresses[j], *endpoints[i].Hostname, *endpoints[i].NodeName)
 						} else {
-							log.Debugf("[%s] found endpoint - address: %s, node: %s", ep.label, ep.endpoints.Endpoints[i].Addresses[j], *ep.endpoints.Endpoints[i].NodeName)
+							log.Debugf("[%s] found endpoint - address: %s, node: %s", ep.label, endpoints[i].Addresses[j], *endpoints[i].NodeName)
 						}
-						localEndpoints = append(localEndpoints, ep.endpoints.Endpoints[i].Addresses[j])
+						localEndpoints = append(localEndpoints, endpoints[i].Addresses[j])
 						// 3. Compare to shortname
-					} else if shortnameErr != nil && shortname == *ep.endpoints.Endpoints[i].NodeName && *ep.endpoints.Endpoints[i].Conditions.Serving {
-						log.Debugf("[%s] found endpoint - address: %s, shortname: %s, node: %s", ep.label, ep.endpoints.Endpoints[i].Addresses[j], shortname, *ep.endpoints.Endpoints[i].NodeName)
-						localEndpoints = append(localEndpoints, ep.endpoints.Endpoints[i].Addresses[j])
+					} else if shortnameErr != nil && shortname == *endpoints[i].NodeName && *endpoints[i].Conditions.Serving {
+						log.Debugf("[%s] found endpoint - address: %s, shortname: %s, node: %s", ep.label, endpoints[i].Addresses[j], shortname, *endpoints[i].NodeName)
+						localEndpoints = append(localEndpoints, endpoints[i].Addresses[j])
 					}
 				}
 			}
 		}
 	}
-	return localEndpoints, nil
+	return uniqueAddresses(localEndpoints), nil
+}
+
+// getLocalEndpointsByZone selects node-local endpoints first; when none exist
+// and a zone is given, serving endpoints in the same topology zone are used
+// instead. Endpoints without zone information keep the node-local behaviour.
+func (ep *endpointslicesProvider) getLocalEndpointsByZone(id, zone string, config *kubevip.Config) ([]string, error) {
+	localEndpoints, err := ep.getLocalEndpoints(id, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(localEndpoints) > 0 || zone == "" {
+		return localEndpoints, nil
+	}
+
+	endpoints := ep.servingEndpoints()
+	for i := range endpoints {
+		if endpoints[i].Zone == nil || *endpoints[i].Zone != zone {
+			continue
+		}
+		if endpoints[i].Conditions.Serving == nil || !*endpoints[i].Conditions.Serving {
+			continue
+		}
+		log.Debugf("[%s] found same-zone endpoint - addresses: %v, zone: %s", ep.label, endpoints[i].Addresses, zone)
+		localEndpoints = append(localEndpoints, endpoints[i].Addresses...)
+	}
+	return uniqueAddresses(localEndpoints), nil
 }
 
 func (ep *endpointslicesProvider) updateServiceAnnotation(endpoint, endpointIPv6 string, service *v1.Service, sm *Manager) error {
@@ -0,0 +1,160 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/kube-vip/kube-vip/pkg/kubevip"
+)
+
+func Test_getLocalEndpointsByZone(t *testing.T) {
+	nodeName := "node-a"
+	otherNode := "node-b"
+	zone := "eu-west-1a"
+	otherZone := "eu-west-1b"
+	serving := true
+
+	provider := &endpointslicesProvider{
+		label: "slices-test",
+		endpoints: &discoveryv1.EndpointSlice{
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses:  []string{"10.0.0.1"},
+					NodeName:   &otherNode,
+					Zone:       &zone,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving},
+				},
+				{
+					Addresses:  []string{"10.0.0.2"},
+					NodeName:   &otherNode,
+					Zone:       &otherZone,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving},
+				},
+			},
+		},
+	}
+	config := &kubevip.Config{}
+
+	// With no node-local endpoints the same-zone endpoint is selected
+	endpoints, err := provider.getLocalEndpointsByZone(nodeName, zone, config)
+	if err != nil {
+		t.Fatalf("getLocalEndpointsByZone() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.1"}) {
+		t.Errorf("getLocalEndpointsByZone() = %v, want [10.0.0.1]", endpoints)
+	}
+
+	// Without a zone the behaviour matches the node-local selection
+	endpoints, err = provider.getLocalEndpointsByZone(nodeName, "", config)
+	if err != nil {
+		t.Fatalf("getLocalEndpointsByZone() error = %v", err)
+	}
+	if len(endpoints) != 0 {
+		t.Errorf("getLocalEndpointsByZone() = %v, want no endpoints", endpoints)
+	}
+
+	// A node-local endpoint takes precedence over same-zone ones
+	provider.endpoints.Endpoints[1].NodeName = &nodeName
+	endpoints, err = provider.getLocalEndpointsByZone(nodeName, zone, config)
+	if err != nil {
+		t.Fatalf("getLocalEndpointsByZone() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.2"}) {
+		t.Errorf("getLocalEndpointsByZone() = %v, want [10.0.0.2]", endpoints)
+	}
+}
+
+func Test_servingEndpointsExcludeTerminating(t *testing.T) {
+	nodeName := "node-a"
+	serving := true
+	terminating := true
+
+	provider := &endpointslicesProvider{
+		label: "slices-test",
+		endpoints: &discoveryv1.EndpointSlice{
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses:  []string{"10.0.0.1"},
+					NodeName:   &nodeName,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving, Terminating: &terminating},
+				},
+				{
+					Addresses:  []string{"10.0.0.2"},
+					NodeName:   &nodeName,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving},
+				},
+			},
+		},
+	}
+
+	// The terminating endpoint is dropped while a ready one remains
+	endpoints, err := provider.getAllEndpoints()
+	if err != nil {
+		t.Fatalf("getAllEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.2"}) {
+		t.Errorf("getAllEndpoints() = %v, want [10.0.0.2]", endpoints)
+	}
+
+	endpoints, err = provider.getLocalEndpoints(nodeName, &kubevip.Config{})
+	if err != nil {
+		t.Fatalf("getLocalEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.2"}) {
+		t.Errorf("getLocalEndpoints() = %v, want [10.0.0.2]", endpoints)
+	}
+
+	// Once every endpoint is terminating they are kept, so the service
+	// isn't blackholed during a rollout
+	provider.endpoints.Endpoints[1].Conditions.Terminating = &terminating
+	endpoints, err = provider.getAllEndpoints()
+	if err != nil {
+		t.Fatalf("getAllEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("getAllEndpoints() = %v, want [10.0.0.1 10.0.0.2]", endpoints)
+	}
+}
+
+func Test_getAllEndpointsDeduplicates(t *testing.T) {
+	nodeName := "node-a"
+	serving := true
+
+	// The same address mirrored into two slices entries must only be
+	// advertised once
+	provider := &endpointslicesProvider{
+		label: "slices-test",
+		endpoints: &discoveryv1.EndpointSlice{
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses:  []string{"10.0.0.1", "10.0.0.2"},
+					NodeName:   &nodeName,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving},
+				},
+				{
+					Addresses:  []string{"10.0.0.1"},
+					NodeName:   &nodeName,
+					Conditions: discoveryv1.EndpointConditions{Serving: &serving},
+				},
+			},
+		},
+	}
+
+	endpoints, err := provider.getAllEndpoints()
+	if err != nil {
+		t.Fatalf("getAllEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("getAllEndpoints() = %v, want [10.0.0.1 10.0.0.2]", endpoints)
+	}
+
+	endpoints, err = provider.getLocalEndpoints(nodeName, &kubevip.Config{})
+	if err != nil {
+		t.Fatalf("getLocalEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("getLocalEndpoints() = %v, want [10.0.0.1 10.0.0.2]", endpoints)
+	}
+}
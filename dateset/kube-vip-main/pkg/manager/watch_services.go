@@ -44,6 +44,36 @@ func init() {
 	watchedService = make(map[string]bool)
 }
 
+// The activeServiceLoadBalancer maps are written by the services watcher and
+// read/cleared by the node drain watcher, so all access goes through these
+// helpers, which are guarded by the manager mutex.
+
+// startServiceElection creates the context a service election runs under and
+// tracks its cancel function
+func (sm *Manager) startServiceElection(uid string) context.Context {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	activeServiceLoadBalancer[uid], activeServiceLoadBalancerCancel[uid] = context.WithCancel(context.TODO())
+	return activeServiceLoadBalancer[uid]
+}
+
+// serviceElectionCtx returns the context a service election runs under, or nil
+func (sm *Manager) serviceElectionCtx(uid string) context.Context {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	return activeServiceLoadBalancer[uid]
+}
+
+// cancelServiceElection cancels the election of a single service, if any
+func (sm *Manager) cancelServiceElection(uid string) {
+	sm.mutex.Lock()
+	cancel := activeServiceLoadBalancerCancel[uid]
+	sm.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
 // This function handles the watching of a services endpoints and updates a load balancers endpoint configurations accordingly
 func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context.Context, *v1.Service, *sync.WaitGroup) error) error {
 	// Watch function
@@ -150,7 +180,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 				log.Debugf("(svcs) [%s] has been added/modified with addresses [%s]", svc.Name, fetchServiceAddresses(svc))
 
 				wg.Add(1)
-				activeServiceLoadBalancer[string(svc.UID)], activeServiceLoadBalancerCancel[string(svc.UID)] = context.WithCancel(context.TODO())
+				sm.startServiceElection(string(svc.UID))
 				// Background the services election
 				// EnableServicesElection enabled
 				// watchEndpoint will do a ServicesElection by Service and understands local endpoints
@@ -174,7 +204,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 									} else {
 										provider = &endpointslicesProvider{label: "endpointslices"}
 									}
-									if err = sm.watchEndpoint(activeServiceLoadBalancer[string(svc.UID)], id, svc, &wg, provider); err != nil {
+									if err = sm.watchEndpoint(sm.serviceElectionCtx(string(svc.UID)), id, svc, &wg, provider); err != nil {
 										log.Error(err)
 									}
 									wg.Done()
@@ -184,7 +214,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 							if (sm.config.EnableRoutingTable || sm.config.EnableBGP) && (!sm.config.EnableLeaderElection && !sm.config.EnableServicesElection) {
 								wg.Add(1)
 								go func() {
-									err = serviceFunc(activeServiceLoadBalancer[string(svc.UID)], svc, &wg)
+									err = serviceFunc(sm.serviceElectionCtx(string(svc.UID)), svc, &wg)
 									if err != nil {
 										log.Error(err)
 									}
@@ -206,7 +236,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 								} else {
 									provider = &endpointslicesProvider{label: "endpointslices"}
 								}
-								if err = sm.watchEndpoint(activeServiceLoadBalancer[string(svc.UID)], id, svc, &wg, provider); err != nil {
+								if err = sm.watchEndpoint(sm.serviceElectionCtx(string(svc.UID)), id, svc, &wg, provider); err != nil {
 									log.Error(err)
 								}
 								wg.Done()
@@ -215,7 +245,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 
 						wg.Add(1)
 						go func() {
-							err = serviceFunc(activeServiceLoadBalancer[string(svc.UID)], svc, &wg)
+							err = serviceFunc(sm.serviceElectionCtx(string(svc.UID)), svc, &wg)
 							if err != nil {
 								log.Error(err)
 							}
@@ -225,7 +255,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 						// Increment the waitGroup before the service Func is called (Done is completed in there)
 						wg.Add(1)
 						go func() {
-							err = serviceFunc(activeServiceLoadBalancer[string(svc.UID)], svc, &wg)
+							err = serviceFunc(sm.serviceElectionCtx(string(svc.UID)), svc, &wg)
 							if err != nil {
 								log.Error(err)
 							}
@@ -236,7 +266,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 				} else {
 					// Increment the waitGroup before the service Func is called (Done is completed in there)
 					wg.Add(1)
-					err = serviceFunc(activeServiceLoadBalancer[string(svc.UID)], svc, &wg)
+					err = serviceFunc(sm.serviceElectionCtx(string(svc.UID)), svc, &wg)
 					if err != nil {
 						log.Error(err)
 					}
@@ -280,9 +310,7 @@ func (sm *Manager) servicesWatcher(ctx context.Context, serviceFunc func(context
 				}
 
 				// Calls the cancel function of the context
-				if activeServiceLoadBalancerCancel[string(svc.UID)] != nil {
-					activeServiceLoadBalancerCancel[string(svc.UID)]()
-				}
+				sm.cancelServiceElection(string(svc.UID))
 				activeService[string(svc.UID)] = false
 				watchedService[string(svc.UID)] = false
 			}
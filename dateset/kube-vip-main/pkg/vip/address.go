@@ -35,6 +35,7 @@ type Network interface {
 	IP() string
 	PrepareRoute() *netlink.Route
 	SetIP(ip string) error
+	SetRouteMTU(mtu int)
 	SetServicePorts(service *v1.Service)
 	Interface() string
 	IsDADFAIL() bool
@@ -60,6 +61,7 @@ type network struct {
 	routeTable       int
 	routingTableType int
 	routingProtocol  int
+	routeMTU         int
 }
 
 func netlinkParse(addr string) (*netlink.Addr, error) {
@@ -187,9 +189,19 @@ func (configurator *network) PrepareRoute() *netlink.Route {
 		Type:      configurator.routingTableType,
 		Protocol:  netlink.RouteProtocol(configurator.routingProtocol),
 	}
+	// Lower the path MTU when the route traverses links with a smaller MTU,
+	// to avoid fragmentation blackholes
+	if configurator.routeMTU > 0 {
+		route.MTU = configurator.routeMTU
+	}
 	return route
 }
 
+// SetRouteMTU - set the MTU used for routes to this VIP; 0 keeps the link default
+func (configurator *network) SetRouteMTU(mtu int) {
+	configurator.routeMTU = mtu
+}
+
 // AddRoute - Add an IP address to a route table
 func (configurator *network) AddRoute() error {
 	route := configurator.PrepareRoute()
@@ -0,0 +1,32 @@
+package vip
+
+import (
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestPrepareRouteMTU(t *testing.T) {
+	address, err := netlink.ParseAddr("192.168.0.100/32")
+	if err != nil {
+		t.Fatalf("failed to parse address: %v", err)
+	}
+	configurator := &network{
+		address:    address,
+		link:       &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Index: 3}},
+		routeTable: 198,
+	}
+
+	// Without a configured MTU the route keeps the link default
+	route := configurator.PrepareRoute()
+	if route.MTU != 0 {
+		t.Errorf("expected no route MTU, got %d", route.MTU)
+	}
+
+	// A configured MTU is applied to the prepared route
+	configurator.SetRouteMTU(1380)
+	route = configurator.PrepareRoute()
+	if route.MTU != 1380 {
+		t.Errorf("expected route MTU 1380, got %d", route.MTU)
+	}
+}
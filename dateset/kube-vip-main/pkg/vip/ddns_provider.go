@@ -0,0 +1,75 @@
+package vip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DDNSProvider advertises the VIP's current address to an external dynamic
+// DNS service, so clients that resolve the DNS name follow the VIP across
+// failover.
+type DDNSProvider interface {
+	Update(ctx context.Context, hostname, ip string) error
+}
+
+// DDNSProviderConfig holds the settings for an external dynamic DNS provider
+type DDNSProviderConfig struct {
+	// Endpoint is the provider's update URL (dyndns2-style API)
+	Endpoint string
+	// Username and Password are used for HTTP basic authentication
+	Username string
+	Password string
+}
+
+type webDDNSProvider struct {
+	config DDNSProviderConfig
+	client *http.Client
+}
+
+// NewWebDDNSProvider returns a DDNSProvider that pushes updates over the
+// dyndns2-style HTTP API most providers expose
+func NewWebDDNSProvider(config DDNSProviderConfig) DDNSProvider {
+	return &webDDNSProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Update sends the hostname and its current address to the provider endpoint
+func (p *webDDNSProvider) Update(ctx context.Context, hostname, ip string) error {
+	u, err := url.Parse(p.config.Endpoint)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("hostname", hostname)
+	q.Set("myip", ip)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if p.config.Username != "" {
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("ddns update for [%s] failed with status [%d]: %s", hostname, resp.StatusCode, string(body))
+	}
+	log.Infof("advertised [%s] for the DNS name [%s]", ip, hostname)
+	return nil
+}
@@ -1,7 +1,9 @@
 package vip
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
@@ -29,14 +31,28 @@ import (
 const MangleChainName = "KUBE-VIP-EGRESS"
 const Comment = "a3ViZS12aXAK=kube-vip"
 
+// DefaultFWMark is the mark used for egress traffic unless a custom one is configured
+const DefaultFWMark = "64/64"
+
 type Egress struct {
 	ipTablesClient *iptables.IPTables
 	comment        string
+	protocol       iptables.Protocol
+
+	// DryRun only logs the rules that would be added or deleted, without
+	// touching the tables; useful to rehearse egress changes on a node
+	DryRun bool
+
+	// FWMark is the firewall mark (value/mask) used to identify egress traffic;
+	// configurable so it won't collide with CNIs that also mark with 64/64
+	FWMark string
 }
 
-func CreateIptablesClient(nftables bool, namespace string, protocol iptables.Protocol) (*Egress, error) {
-	log.Infof("[egress] Creating an iptables client, nftables mode [%t]", nftables)
+func CreateIptablesClient(nftables bool, namespace string, protocol iptables.Protocol, dryRun bool, fwmark string) (*Egress, error) {
+	log.Infof("[egress] Creating an iptables client, nftables mode [%t], dry-run [%t]", nftables, dryRun)
 	e := new(Egress)
+	e.DryRun = dryRun
+	e.FWMark = fwmark
 	var err error
 
 	options := []iptables.Option{}
@@ -48,9 +64,63 @@ func CreateIptablesClient(nftables bool, namespace string, protocol iptables.Pro
 
 	e.ipTablesClient, err = iptables.New(options...)
 	e.comment = Comment + "-" + namespace
+	e.protocol = protocol
 	return e, err
 }
 
+// logDryRun logs the rule an operation would have applied, using the same
+// argument slice that would have been passed to the iptables client
+func (e *Egress) logDryRun(op, table, chain string, rulespec ...string) {
+	log.Infof("[egress] dry-run: would %s rule in %s/%s: [%s]", op, table, chain, strings.Join(rulespec, " "))
+}
+
+// natCIDR returns the pod address as a host CIDR for the family this client
+// was created for, i.e. <podIP>/32 for IPv4 and <podIP>/128 for IPv6
+func (e *Egress) natCIDR(podIP string) string {
+	if e.protocol == iptables.ProtocolIPv6 {
+		return podIP + "/128"
+	}
+	return podIP + "/32"
+}
+
+// fwmark returns the configured firewall mark, falling back to the default
+func (e *Egress) fwmark() string {
+	if e.FWMark != "" {
+		return e.FWMark
+	}
+	return DefaultFWMark
+}
+
+// markingRuleSpec returns the argument list for the mangle rule that marks traffic from the given network
+func (e *Egress) markingRuleSpec(subnet string) []string {
+	return []string{"-s", subnet, "-j", "MARK", "--set-mark", e.fwmark(), "-m", "comment", "--comment", e.comment}
+}
+
+// destinationMarkingRuleSpec returns the argument list for the mangle rule that marks traffic towards the given network
+func (e *Egress) destinationMarkingRuleSpec(subnet string) []string {
+	return []string{"-d", subnet, "-j", "MARK", "--set-mark", e.fwmark(), "-m", "comment", "--comment", e.comment}
+}
+
+// sourceNatRuleSpec returns the argument list for the SNAT rule translating marked traffic from podIP to the vip
+func (e *Egress) sourceNatRuleSpec(podIP, vip string) []string {
+	return []string{"-s", e.natCIDR(podIP), "-m", "mark", "--mark", e.fwmark(), "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment}
+}
+
+// sourceNatForPortRuleSpec is sourceNatRuleSpec limited to a single destination port; the
+// explicit "-m <proto>" match is required for --dport to translate correctly under nftables
+func (e *Egress) sourceNatForPortRuleSpec(podIP, vip, port, proto string) []string {
+	return []string{"-s", e.natCIDR(podIP), "-m", "mark", "--mark", e.fwmark(), "-j", "SNAT", "--to-source", vip, "-p", proto, "-m", proto, "--dport", port, "-m", "comment", "--comment", e.comment}
+}
+
+// validateProtocol ensures proto can carry a destination port in a SNAT rule
+func validateProtocol(proto string) error {
+	switch proto {
+	case "tcp", "udp", "sctp":
+		return nil
+	}
+	return fmt.Errorf("[egress] unsupported protocol [%s], expected tcp/udp/sctp", proto)
+}
+
 func (e *Egress) CheckMangleChain(name string) (bool, error) {
 	log.Infof("[egress] Checking for Chain [%s]", name)
 	return e.ipTablesClient.ChainExists("mangle", name)
@@ -67,34 +137,37 @@ func (e *Egress) DeleteManglePrerouting(name string) error {
 func (e *Egress) DeleteMangleMarking(podIP, name string) error {
 	log.Infof("[egress] Stopping marking packets on network [%s]", podIP)
 
-	exists, _ := e.ipTablesClient.Exists("mangle", name, "-s", podIP, "-j", "MARK", "--set-mark", "64/64", "-m", "comment", "--comment", e.comment)
+	exists, _ := e.ipTablesClient.Exists("mangle", name, e.markingRuleSpec(podIP)...)
 
 	if !exists {
 		return fmt.Errorf("unable to find source Mangle rule for [%s]", podIP)
 	}
-	return e.ipTablesClient.Delete("mangle", name, "-s", podIP, "-j", "MARK", "--set-mark", "64/64", "-m", "comment", "--comment", e.comment)
+	return e.ipTablesClient.Delete("mangle", name, e.markingRuleSpec(podIP)...)
 }
 
 func (e *Egress) DeleteSourceNat(podIP, vip string) error {
 	log.Infof("[egress] Removing source nat from [%s] => [%s]", podIP, vip)
 
-	exists, _ := e.ipTablesClient.Exists("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment)
+	exists, _ := e.ipTablesClient.Exists("nat", "POSTROUTING", e.sourceNatRuleSpec(podIP, vip)...)
 
 	if !exists {
 		return fmt.Errorf("unable to find source Nat rule for [%s]", podIP)
 	}
-	return e.ipTablesClient.Delete("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment)
+	return e.ipTablesClient.Delete("nat", "POSTROUTING", e.sourceNatRuleSpec(podIP, vip)...)
 }
 
 func (e *Egress) DeleteSourceNatForDestinationPort(podIP, vip, port, proto string) error {
 	log.Infof("[egress] Adding source nat from [%s] => [%s]", podIP, vip)
+	if err := validateProtocol(proto); err != nil {
+		return err
+	}
 
-	exists, _ := e.ipTablesClient.Exists("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-p", proto, "--dport", port, "-m", "comment", "--comment", e.comment)
+	exists, _ := e.ipTablesClient.Exists("nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...)
 
 	if !exists {
 		return fmt.Errorf("unable to find source Nat rule for [%s], with destination port [%s]", podIP, port)
 	}
-	return e.ipTablesClient.Delete("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-p", proto, "--dport", port, "-m", "comment", "--comment", e.comment)
+	return e.ipTablesClient.Delete("nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...)
 }
 
 func (e *Egress) CreateMangleChain(name string) error {
@@ -115,13 +188,45 @@ func (e *Egress) AppendReturnRulesForDestinationSubnet(name, subnet string) erro
 
 func (e *Egress) AppendReturnRulesForMarking(name, subnet string) error {
 	log.Infof("[egress] Marking packets on network [%s]", subnet)
-	exists, _ := e.ipTablesClient.Exists("mangle", name, "-s", subnet, "-j", "MARK", "--set-mark", "64/64", "-m", "comment", "--comment", e.comment)
+	exists, _ := e.ipTablesClient.Exists("mangle", name, e.markingRuleSpec(subnet)...)
+	if !exists {
+		if e.DryRun {
+			e.logDryRun("append", "mangle", name, e.markingRuleSpec(subnet)...)
+			return nil
+		}
+		return e.ipTablesClient.Append("mangle", name, e.markingRuleSpec(subnet)...)
+	}
+	return nil
+}
+
+// AppendMarkRulesForDestinationSubnet marks packets whose destination matches subnet,
+// so egress SNAT only applies to traffic towards specific external networks; it
+// composes with AppendReturnRulesForMarking, which marks by source
+func (e *Egress) AppendMarkRulesForDestinationSubnet(name, subnet string) error {
+	log.Infof("[egress] Marking packets towards network [%s]", subnet)
+	exists, _ := e.ipTablesClient.Exists("mangle", name, e.destinationMarkingRuleSpec(subnet)...)
 	if !exists {
-		return e.ipTablesClient.Append("mangle", name, "-s", subnet, "-j", "MARK", "--set-mark", "64/64", "-m", "comment", "--comment", e.comment)
+		if e.DryRun {
+			e.logDryRun("append", "mangle", name, e.destinationMarkingRuleSpec(subnet)...)
+			return nil
+		}
+		return e.ipTablesClient.Append("mangle", name, e.destinationMarkingRuleSpec(subnet)...)
 	}
 	return nil
 }
 
+// DeleteMangleMarkingForDestination removes the rule added by AppendMarkRulesForDestinationSubnet
+func (e *Egress) DeleteMangleMarkingForDestination(subnet, name string) error {
+	log.Infof("[egress] Stopping marking packets towards network [%s]", subnet)
+
+	exists, _ := e.ipTablesClient.Exists("mangle", name, e.destinationMarkingRuleSpec(subnet)...)
+
+	if !exists {
+		return fmt.Errorf("unable to find destination Mangle rule for [%s]", subnet)
+	}
+	return e.ipTablesClient.Delete("mangle", name, e.destinationMarkingRuleSpec(subnet)...)
+}
+
 func (e *Egress) InsertMangeTableIntoPrerouting(name string) error {
 	log.Infof("[egress] Adding jump from mangle prerouting to [%s]", name)
 	if exists, err := e.ipTablesClient.Exists("mangle", "PREROUTING", "-j", name, "-m", "comment", "--comment", e.comment); err != nil {
@@ -137,19 +242,22 @@ func (e *Egress) InsertMangeTableIntoPrerouting(name string) error {
 
 func (e *Egress) InsertSourceNat(vip, podIP string) error {
 	log.Infof("[egress] Adding source nat from [%s] => [%s]", podIP, vip)
-	if exists, err := e.ipTablesClient.Exists("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment); err != nil {
+	if exists, err := e.ipTablesClient.Exists("nat", "POSTROUTING", e.sourceNatRuleSpec(podIP, vip)...); err != nil {
 		return err
 	} else if exists {
-		if err2 := e.ipTablesClient.Delete("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment); err2 != nil {
+		if err2 := e.ipTablesClient.Delete("nat", "POSTROUTING", e.sourceNatRuleSpec(podIP, vip)...); err2 != nil {
 			return err2
 		}
 	}
 
-	return e.ipTablesClient.Insert("nat", "POSTROUTING", 1, "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-m", "comment", "--comment", e.comment)
+	return e.ipTablesClient.Insert("nat", "POSTROUTING", 1, e.sourceNatRuleSpec(podIP, vip)...)
 }
 
 func (e *Egress) InsertSourceNatForDestinationPort(vip, podIP, port, proto string) error {
 	log.Infof("[egress] Adding source nat from [%s] => [%s], with destination port [%s]", podIP, vip, port)
+	if err := validateProtocol(proto); err != nil {
+		return err
+	}
 	natRules, err := e.ipTablesClient.List("nat", "POSTROUTING")
 	if err != nil {
 		return err
@@ -157,21 +265,31 @@ func (e *Egress) InsertSourceNatForDestinationPort(vip, podIP, port, proto strin
 	foundNatRules := e.findExistingVIP(natRules, vip)
 	log.Warnf("[egress] Cleaning [%d] existing postrouting nat rules for vip [%s]", len(foundNatRules), vip)
 	for x := range foundNatRules {
+		if e.DryRun {
+			e.logDryRun("delete", "nat", "POSTROUTING", foundNatRules[x][2:]...)
+			continue
+		}
 		err = e.ipTablesClient.Delete("nat", "POSTROUTING", foundNatRules[x][2:]...)
 		if err != nil {
 			log.Errorf("[egress] Error removing rule [%v]", err)
 		}
 	}
 
-	if exists, err := e.ipTablesClient.Exists("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-p", proto, "--dport", port, "-m", "comment", "--comment", e.comment); err != nil {
+	if exists, err := e.ipTablesClient.Exists("nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...); err != nil {
 		return err
 	} else if exists {
-		if err2 := e.ipTablesClient.Delete("nat", "POSTROUTING", "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-p", proto, "--dport", port, "-m", "comment", "--comment", e.comment); err2 != nil {
+		if e.DryRun {
+			e.logDryRun("delete", "nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...)
+		} else if err2 := e.ipTablesClient.Delete("nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...); err2 != nil {
 			return err2
 		}
 	}
 
-	return e.ipTablesClient.Insert("nat", "POSTROUTING", 1, "-s", podIP+"/32", "-m", "mark", "--mark", "64/64", "-j", "SNAT", "--to-source", vip, "-p", proto, "--dport", port, "-m", "comment", "--comment", e.comment)
+	if e.DryRun {
+		e.logDryRun("insert", "nat", "POSTROUTING", e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...)
+		return nil
+	}
+	return e.ipTablesClient.Insert("nat", "POSTROUTING", 1, e.sourceNatForPortRuleSpec(podIP, vip, port, proto)...)
 }
 
 func DeleteExistingSessions(sessionIP string, destination bool, destinationPorts, srcPorts string) error {
@@ -182,7 +300,13 @@ func DeleteExistingSessions(sessionIP string, destination bool, destinationPorts
 		return err
 	}
 	defer nfct.Close()
-	sessions, err := nfct.Dump(ct.Conntrack, ct.IPv4)
+	// Dump the conntrack table for the session address family, so IPv6 VIPs
+	// have their stale sessions flushed as well
+	family := ct.IPv4
+	if ip := net.ParseIP(sessionIP); ip != nil && ip.To4() == nil {
+		family = ct.IPv6
+	}
+	sessions, err := nfct.Dump(ct.Conntrack, family)
 	if err != nil {
 		log.Errorf("could not dump sessions: %v", err)
 		return err
@@ -251,10 +375,10 @@ func DeleteExistingSessions(sessionIP string, destination bool, destinationPorts
 					proto := destPortProtocol[*session.Origin.Proto.DstPort]
 					if proto == *session.Origin.Proto.Number {
 						log.Infof("[egress] cleaning existing connection Source [%s] -> [%s:%d] proto: [%d] ", session.Origin.Src.String(), session.Origin.Dst.String(), *session.Origin.Proto.DstPort, *session.Origin.Proto.Number)
-						err = nfct.Delete(ct.Conntrack, ct.IPv4, session)
+						err = nfct.Delete(ct.Conntrack, family, session)
 					}
 				} else {
-					err = nfct.Delete(ct.Conntrack, ct.IPv4, session)
+					err = nfct.Delete(ct.Conntrack, family, session)
 				}
 				if err != nil {
 					log.Errorf("could not delete sessions: %v", err)
@@ -271,10 +395,10 @@ func DeleteExistingSessions(sessionIP string, destination bool, destinationPorts
 					proto := srcPortProtocol[*session.Origin.Proto.DstPort]
 					if proto == *session.Origin.Proto.Number {
 						log.Infof("[egress] cleaning existing connection Source [%s] -> [%s:%d] proto: [%d] ", session.Origin.Src.String(), session.Origin.Dst.String(), *session.Origin.Proto.DstPort, *session.Origin.Proto.Number)
-						err = nfct.Delete(ct.Conntrack, ct.IPv4, session)
+						err = nfct.Delete(ct.Conntrack, family, session)
 					}
 				} else {
-					err = nfct.Delete(ct.Conntrack, ct.IPv4, session)
+					err = nfct.Delete(ct.Conntrack, family, session)
 				}
 				if err != nil {
 					log.Errorf("could not delete sessions: %v", err)
@@ -305,12 +429,20 @@ func (e *Egress) CleanIPtables() error {
 	if err != nil {
 		return err
 	}
+	// Accumulate per-rule failures so the caller knows cleanup was partial,
+	// while still attempting to remove every rule
+	var cleanErrs []error
 	foundNatRules := e.findRules(natRules)
 	log.Warnf("[egress] Cleaning [%d] dangling postrouting nat rules", len(foundNatRules))
 	for x := range foundNatRules {
+		if e.DryRun {
+			e.logDryRun("delete", "nat", "POSTROUTING", foundNatRules[x][2:]...)
+			continue
+		}
 		err = e.ipTablesClient.Delete("nat", "POSTROUTING", foundNatRules[x][2:]...)
 		if err != nil {
 			log.Errorf("[egress] Error removing rule [%v]", err)
+			cleanErrs = append(cleanErrs, fmt.Errorf("removing nat rule [%s]: %w", strings.Join(foundNatRules[x][2:], " "), err))
 		}
 	}
 	exists, err := e.CheckMangleChain(MangleChainName)
@@ -325,9 +457,14 @@ func (e *Egress) CleanIPtables() error {
 		foundNatRules = e.findRules(mangleRules)
 		log.Warnf("[egress] Cleaning [%d] dangling prerouting mangle rules", len(foundNatRules))
 		for x := range foundNatRules {
+			if e.DryRun {
+				e.logDryRun("delete", "mangle", MangleChainName, foundNatRules[x][2:]...)
+				continue
+			}
 			err = e.ipTablesClient.Delete("mangle", MangleChainName, foundNatRules[x][2:]...)
 			if err != nil {
 				log.Errorf("[egress] Error removing rule [%v]", err)
+				cleanErrs = append(cleanErrs, fmt.Errorf("removing mangle rule [%s]: %w", strings.Join(foundNatRules[x][2:], " "), err))
 			}
 		}
 
@@ -341,7 +478,63 @@ func (e *Egress) CleanIPtables() error {
 	} else {
 		log.Warnf("No existing mangle chain [%s] exists", MangleChainName)
 	}
-	return nil
+	return errors.Join(cleanErrs...)
+}
+
+// Rule describes a single kube-vip-managed iptables rule as returned by AuditRules
+type Rule struct {
+	Table string   // table containing the rule ("mangle" or "nat")
+	Chain string   // chain containing the rule
+	Spec  []string // the rule arguments, without the leading "-A <chain>"
+	SNAT  bool     // true if the rule performs source nat
+	Mark  bool     // true if the rule marks packets
+}
+
+// AuditRules returns every rule tagged with this client's comment across the
+// nat POSTROUTING chain and the kube-vip mangle chain, so callers (e.g. a
+// health endpoint) can inspect the current egress state without mutating it
+func (e *Egress) AuditRules() ([]Rule, error) {
+	var audited []Rule
+
+	natRules, err := e.ipTablesClient.List("nat", "POSTROUTING")
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range e.findRules(natRules) {
+		audited = append(audited, classifyRule("nat", "POSTROUTING", r))
+	}
+
+	exists, err := e.CheckMangleChain(MangleChainName)
+	if err != nil {
+		log.Debugf("[egress] No Mangle chain exists [%v]", err)
+	}
+	if exists {
+		mangleRules, err := e.ipTablesClient.List("mangle", MangleChainName)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range e.findRules(mangleRules) {
+			audited = append(audited, classifyRule("mangle", MangleChainName, r))
+		}
+	}
+	return audited, nil
+}
+
+// classifyRule turns a rule found by findRules into a Rule, detecting whether
+// it source nats or marks packets by its jump target
+func classifyRule(table, chain string, rule []string) Rule {
+	out := Rule{Table: table, Chain: chain, Spec: rule[2:]}
+	for i := 0; i < len(rule)-1; i++ {
+		if rule[i] == "-j" {
+			switch rule[i+1] {
+			case "SNAT":
+				out.SNAT = true
+			case "MARK":
+				out.Mark = true
+			}
+		}
+	}
+	return out
 }
 
 func (e *Egress) findRules(rules []string) [][]string {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	iptables "github.com/kube-vip/kube-vip/pkg/iptables"
 )
 
 func Test_findRules(t *testing.T) {
@@ -34,3 +36,121 @@ func Test_findRules(t *testing.T) {
 		})
 	}
 }
+
+func Test_classifyRule(t *testing.T) {
+	e := Egress{comment: Comment + "-" + "default"}
+	tests := []struct {
+		name  string
+		table string
+		chain string
+		rule  []string
+		want  Rule
+	}{
+		{
+			"mark",
+			"mangle", MangleChainName,
+			[]string{"-A", MangleChainName, "-s", "172.17.88.190/32", "-m", "comment", "--comment", e.comment, "-j", "MARK", "--set-xmark", "0x40/0x40"},
+			Rule{Table: "mangle", Chain: MangleChainName, Spec: []string{"-s", "172.17.88.190/32", "-m", "comment", "--comment", e.comment, "-j", "MARK", "--set-xmark", "0x40/0x40"}, Mark: true},
+		},
+		{
+			"snat",
+			"nat", "POSTROUTING",
+			[]string{"-A", "POSTROUTING", "-s", "172.17.88.190/32", "-m", "mark", "--mark", DefaultFWMark, "-j", "SNAT", "--to-source", "192.168.0.10"},
+			Rule{Table: "nat", Chain: "POSTROUTING", Spec: []string{"-s", "172.17.88.190/32", "-m", "mark", "--mark", DefaultFWMark, "-j", "SNAT", "--to-source", "192.168.0.10"}, SNAT: true},
+		},
+		{
+			"return",
+			"mangle", MangleChainName,
+			[]string{"-A", MangleChainName, "-d", "10.96.0.0/12", "-j", "RETURN", "-m", "comment", "--comment", e.comment},
+			Rule{Table: "mangle", Chain: MangleChainName, Spec: []string{"-d", "10.96.0.0/12", "-j", "RETURN", "-m", "comment", "--comment", e.comment}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRule(tt.table, tt.chain, tt.rule); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("classifyRule() = \n%v, want \n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_natCIDR(t *testing.T) {
+	e4 := Egress{protocol: iptables.ProtocolIPv4}
+	if got := e4.natCIDR("172.17.88.190"); got != "172.17.88.190/32" {
+		t.Errorf("natCIDR() = %s, want 172.17.88.190/32", got)
+	}
+	e6 := Egress{protocol: iptables.ProtocolIPv6}
+	if got := e6.natCIDR("fd00::190"); got != "fd00::190/128" {
+		t.Errorf("natCIDR() = %s, want fd00::190/128", got)
+	}
+}
+
+func Test_fwmarkRuleSpec(t *testing.T) {
+	e := Egress{comment: Comment + "-" + "default"}
+	spec := e.sourceNatRuleSpec("172.17.88.190", "192.168.0.10")
+	if !contains(spec, DefaultFWMark) {
+		t.Errorf("sourceNatRuleSpec() = %v, want it to contain the default mark %s", spec, DefaultFWMark)
+	}
+
+	e.FWMark = "128/128"
+	for _, spec := range [][]string{
+		e.markingRuleSpec("172.17.88.0/24"),
+		e.sourceNatRuleSpec("172.17.88.190", "192.168.0.10"),
+		e.sourceNatForPortRuleSpec("172.17.88.190", "192.168.0.10", "8080", "tcp"),
+	} {
+		if !contains(spec, "128/128") {
+			t.Errorf("rule spec %v doesn't contain the custom mark 128/128", spec)
+		}
+		if contains(spec, DefaultFWMark) {
+			t.Errorf("rule spec %v still contains the default mark %s", spec, DefaultFWMark)
+		}
+	}
+}
+
+func Test_destinationMarkingRuleSpec(t *testing.T) {
+	e := Egress{comment: Comment + "-" + "default"}
+	want := []string{
+		"-d", "203.0.113.0/24", "-j", "MARK", "--set-mark", DefaultFWMark,
+		"-m", "comment", "--comment", e.comment,
+	}
+	if got := e.destinationMarkingRuleSpec("203.0.113.0/24"); !reflect.DeepEqual(got, want) {
+		t.Errorf("destinationMarkingRuleSpec() = \n%v, want \n%v", got, want)
+	}
+}
+
+func Test_sourceNatForPortRuleSpec(t *testing.T) {
+	e := Egress{comment: Comment + "-" + "default"}
+	for _, proto := range []string{"tcp", "udp", "sctp"} {
+		want := []string{
+			"-s", "172.17.88.190/32", "-m", "mark", "--mark", DefaultFWMark,
+			"-j", "SNAT", "--to-source", "192.168.0.10",
+			"-p", proto, "-m", proto, "--dport", "8080",
+			"-m", "comment", "--comment", e.comment,
+		}
+		if got := e.sourceNatForPortRuleSpec("172.17.88.190", "192.168.0.10", "8080", proto); !reflect.DeepEqual(got, want) {
+			t.Errorf("sourceNatForPortRuleSpec(%s) = \n%v, want \n%v", proto, got, want)
+		}
+	}
+}
+
+func Test_validateProtocol(t *testing.T) {
+	for _, proto := range []string{"tcp", "udp", "sctp"} {
+		if err := validateProtocol(proto); err != nil {
+			t.Errorf("validateProtocol(%s) = %v, want nil", proto, err)
+		}
+	}
+	for _, proto := range []string{"icmp", "TCP", ""} {
+		if err := validateProtocol(proto); err == nil {
+			t.Errorf("validateProtocol(%s) = nil, want error", proto)
+		}
+	}
+}
+
+func contains(spec []string, arg string) bool {
+	for i := range spec {
+		if spec[i] == arg {
+			return true
+		}
+	}
+	return false
+}
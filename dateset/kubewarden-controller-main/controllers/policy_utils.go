@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kubewarden/kubewarden-controller/internal/pkg/admission"
@@ -39,6 +40,54 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+const (
+	uniquelyReachableRequeueBase = 2 * time.Second
+	uniquelyReachableRequeueMax  = 30 * time.Second
+)
+
+type backoffEntry struct {
+	generation int64
+	attempts   uint
+}
+
+// uniquelyReachableBackoff tracks, per policy, how many times reconciliation
+// has been requeued while waiting for the latest replica set to become
+// uniquely reachable, so that the requeue interval grows instead of polling
+// the API server at a fixed rate during long rollouts.
+type uniquelyReachableBackoff struct {
+	mu      sync.Mutex
+	entries map[string]backoffEntry
+}
+
+var policyRequeueBackoff = &uniquelyReachableBackoff{entries: map[string]backoffEntry{}}
+
+// next returns the interval to wait before the next attempt, doubling from
+// uniquelyReachableRequeueBase up to uniquelyReachableRequeueMax. A change of
+// the policy generation restarts the progression.
+func (b *uniquelyReachableBackoff) next(policyName string, generation int64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry := b.entries[policyName]
+	if entry.generation != generation {
+		entry = backoffEntry{generation: generation}
+	}
+	interval := uniquelyReachableRequeueBase << entry.attempts
+	if interval <= 0 || interval > uniquelyReachableRequeueMax {
+		interval = uniquelyReachableRequeueMax
+	}
+	entry.attempts++
+	b.entries[policyName] = entry
+
+	return interval
+}
+
+func (b *uniquelyReachableBackoff) reset(policyName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, policyName)
+}
+
 func setPolicyStatus(ctx context.Context, deploymentsNamespace string, apiReader client.Reader, policy policiesv1.Policy) error {
 	policyServerDeployment := appsv1.Deployment{}
 	if err := apiReader.Get(ctx, types.NamespacedName{Namespace: deploymentsNamespace, Name: naming.PolicyServerDeploymentNameForPolicyServerName(policy.GetPolicyServer())}, &policyServerDeployment); err != nil {
@@ -47,18 +96,24 @@ func setPolicyStatus(ctx context.Context, deploymentsNamespace string, apiReader
 
 	policyServerConfigMap := corev1.ConfigMap{}
 	if err := apiReader.Get(ctx, types.NamespacedName{Namespace: deploymentsNamespace, Name: naming.PolicyServerDeploymentNameForPolicyServerName(policy.GetPolicyServer())}, &policyServerConfigMap); err != nil {
-		return errors.Join(errors.New("could not get configmap"), err)
-	}
-
-	policyMap, err := getPolicyMapFromConfigMap(&policyServerConfigMap)
-	if err == nil {
-		if policyConfig, ok := policyMap[policy.GetUniqueName()]; ok {
-			policy.SetPolicyModeStatus(policiesv1.PolicyModeStatus(policyConfig.PolicyMode))
+		if !apierrors.IsNotFound(err) {
+			return errors.Join(errors.New("could not get configmap"), err)
+		}
+		// the ConfigMap is not created yet during the initial policy server
+		// rollout; report the mode as unknown and still derive the
+		// configuration condition from the deployment alone
+		policy.SetPolicyModeStatus(policiesv1.PolicyModeStatusUnknown)
+	} else {
+		policyMap, err := getPolicyMapFromConfigMap(&policyServerConfigMap)
+		if err == nil {
+			if policyConfig, ok := policyMap[policy.GetUniqueName()]; ok {
+				policy.SetPolicyModeStatus(policiesv1.PolicyModeStatus(policyConfig.PolicyMode))
+			} else {
+				policy.SetPolicyModeStatus(policiesv1.PolicyModeStatusUnknown)
+			}
 		} else {
 			policy.SetPolicyModeStatus(policiesv1.PolicyModeStatusUnknown)
 		}
-	} else {
-		policy.SetPolicyModeStatus(policiesv1.PolicyModeStatusUnknown)
 	}
 
 	policyStatus := policy.GetStatus()
@@ -130,8 +185,9 @@ func reconcilePolicy(ctx context.Context, client client.Client, reconciler admis
 				Message: "The latest replica set is not uniquely reachable",
 			},
 		)
-		return ctrl.Result{Requeue: true, RequeueAfter: 2 * time.Second}, nil
+		return ctrl.Result{Requeue: true, RequeueAfter: policyRequeueBackoff.next(policy.GetUniqueName(), policy.GetGeneration())}, nil
 	}
+	policyRequeueBackoff.reset(policy.GetUniqueName())
 
 	apimeta.SetStatusCondition(
 		&policy.GetStatus().Conditions,
@@ -145,6 +201,7 @@ func reconcilePolicy(ctx context.Context, client client.Client, reconciler admis
 
 	secret := corev1.Secret{}
 	if err := client.Get(ctx, types.NamespacedName{Namespace: reconciler.DeploymentsNamespace, Name: constants.PolicyServerCARootSecretName}, &secret); err != nil {
+		setPolicyAsMissingCARootSecret(policy)
 		return ctrl.Result{}, errors.Join(errors.New("cannot find policy server secret"), err)
 	}
 
@@ -175,6 +232,21 @@ func setPolicyAsActive(policy policiesv1.Policy) {
 	)
 }
 
+// setPolicyAsMissingCARootSecret records on the policy status why the webhook
+// can not be created, so that `kubectl describe` explains the stall instead of
+// the policy silently looping through reconciliation.
+func setPolicyAsMissingCARootSecret(policy policiesv1.Policy) {
+	apimeta.SetStatusCondition(
+		&policy.GetStatus().Conditions,
+		metav1.Condition{
+			Type:    string(policiesv1.PolicyActive),
+			Status:  metav1.ConditionFalse,
+			Reason:  "CARootSecretMissing",
+			Message: fmt.Sprintf("The policy server CA root secret %q can not be read", constants.PolicyServerCARootSecretName),
+		},
+	)
+}
+
 func getPolicyServer(ctx context.Context, client client.Client, policy policiesv1.Policy) (*policiesv1.PolicyServer, error) {
 	policyServer := policiesv1.PolicyServer{}
 	if err := client.Get(ctx, types.NamespacedName{Name: policy.GetPolicyServer()}, &policyServer); err != nil {
@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega" //nolint:revive
+
+	"github.com/kubewarden/kubewarden-controller/internal/pkg/naming"
+	policiesv1 "github.com/kubewarden/kubewarden-controller/pkg/apis/policies/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUniquelyReachableBackoffProgression(t *testing.T) {
+	g := NewWithT(t)
+	backoff := &uniquelyReachableBackoff{entries: map[string]backoffEntry{}}
+
+	expected := []time.Duration{
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		16 * time.Second,
+		30 * time.Second,
+		30 * time.Second,
+	}
+	for _, interval := range expected {
+		g.Expect(backoff.next("policy", 1)).To(Equal(interval))
+	}
+
+	// a new generation restarts the progression
+	g.Expect(backoff.next("policy", 2)).To(Equal(2 * time.Second))
+	g.Expect(backoff.next("policy", 2)).To(Equal(4 * time.Second))
+
+	// other policies are tracked independently
+	g.Expect(backoff.next("another-policy", 1)).To(Equal(2 * time.Second))
+
+	// once the policy is uniquely reachable the backoff is reset
+	backoff.reset("policy")
+	g.Expect(backoff.next("policy", 2)).To(Equal(2 * time.Second))
+}
+
+func TestSetPolicyAsMissingCARootSecret(t *testing.T) {
+	g := NewWithT(t)
+	policy := admissionPolicyFactory("policy", "default", "policy-server", false)
+
+	setPolicyAsMissingCARootSecret(policy)
+
+	condition := apimeta.FindStatusCondition(policy.GetStatus().Conditions, string(policiesv1.PolicyActive))
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal("CARootSecretMissing"))
+}
+
+func TestSetPolicyStatusWithMissingConfigMap(t *testing.T) {
+	g := NewWithT(t)
+	policy := admissionPolicyFactory("policy", "default", "policy-server", false)
+	policyServerDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      naming.PolicyServerDeploymentNameForPolicyServerName(policy.GetPolicyServer()),
+			Namespace: DeploymentsNamespace,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(policiesv1.AddToScheme(scheme)).To(Succeed())
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policyServerDeployment).Build()
+
+	// the ConfigMap is absent: the status update must still go through
+	g.Expect(setPolicyStatus(context.Background(), DeploymentsNamespace, apiReader, policy)).To(Succeed())
+	g.Expect(policy.GetStatus().PolicyMode).To(Equal(policiesv1.PolicyModeStatusUnknown))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lima-vm/lima/pkg/instarchive"
+	"github.com/lima-vm/lima/pkg/store"
+
+	"github.com/spf13/cobra"
+)
+
+func newExportCommand() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:               "export INSTANCE FILE.tar",
+		Short:             "Export an instance as a portable archive",
+		Args:              cobra.ExactArgs(2),
+		RunE:              exportAction,
+		ValidArgsFunction: exportBashComplete,
+		GroupID:           advancedCommand,
+	}
+
+	return exportCmd
+}
+
+func exportAction(_ *cobra.Command, args []string) error {
+	instName := args[0]
+	archivePath := args[1]
+
+	inst, err := store.Inspect(instName)
+	if err != nil {
+		return err
+	}
+	if inst.Status != store.StatusStopped {
+		return fmt.Errorf("expected status %q, got %q", store.StatusStopped, inst.Status)
+	}
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	if err := instarchive.Export(inst.Dir, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func exportBashComplete(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return bashCompleteInstanceNames(cmd)
+}
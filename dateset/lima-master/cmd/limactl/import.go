@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lima-vm/lima/pkg/instarchive"
+	"github.com/lima-vm/lima/pkg/store"
+
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:     "import FILE.tar",
+		Short:   "Import an instance from an archive created by `limactl export`",
+		Args:    cobra.ExactArgs(1),
+		RunE:    importAction,
+		GroupID: advancedCommand,
+	}
+	importCmd.Flags().String("name", "", "name of the new instance (default: archive file name without extension)")
+	importCmd.Flags().Bool("preserve-mac", false, "keep the MAC addresses from the archive instead of regenerating them")
+
+	return importCmd
+}
+
+func importAction(cmd *cobra.Command, args []string) error {
+	archivePath := args[0]
+
+	instName, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+	if instName == "" {
+		base := filepath.Base(archivePath)
+		instName = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	preserveMAC, err := cmd.Flags().GetBool("preserve-mac")
+	if err != nil {
+		return err
+	}
+
+	instDir, err := store.InstanceDir(instName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(instDir); !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("instance %q already exists (%q)", instName, instDir)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := instarchive.Import(f, instDir, preserveMAC); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported instance %q from %q\n", instName, archivePath)
+	return nil
+}
@@ -120,6 +120,8 @@ func newApp() *cobra.Command {
 		newUsernetCommand(),
 		newGenDocCommand(),
 		newSnapshotCommand(),
+		newExportCommand(),
+		newImportCommand(),
 		newProtectCommand(),
 		newUnprotectCommand(),
 	)
@@ -0,0 +1,33 @@
+package hostagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// applyHostCgroupLimits creates a cgroup (v2) for the instance and moves the
+// current process into it, so the VM process spawned by the driver inherits
+// the membership and runs under the configured CPU and memory limits.
+func applyHostCgroupLimits(instName string, cpus float64, memoryBytes int64) error {
+	dir := filepath.Join(cgroupRoot, "lima-"+instName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating cgroup %q: %w", dir, err)
+	}
+	// cpu.max takes "<quota> <period>" in microseconds
+	const period = 100000
+	quota := int64(cpus * period)
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%d %d", quota, period)), 0o644); err != nil {
+		return fmt.Errorf("setting cpu.max: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(memoryBytes, 10)), 0o644); err != nil {
+		return fmt.Errorf("setting memory.max: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		return fmt.Errorf("joining cgroup %q: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package hostagent
+
+import "errors"
+
+func applyHostCgroupLimits(_ string, _ float64, _ int64) error {
+	return errors.New("host cgroup limits are only supported on Linux hosts")
+}
@@ -0,0 +1,224 @@
+// This file defines a DNS server and a DNS handler for serving the guest.
+// The handler answers from the static host entries first and forwards every
+// other query to the configured upstream nameservers.
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+// truncateSize avoids "Parse error" from `busybox nslookup`
+// https://github.com/lima-vm/lima/issues/380
+const truncateSize = 512
+
+var defaultFallbackIPs = []net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("1.1.1.1")}
+
+type HandlerOptions struct {
+	IPv6        bool
+	StaticHosts map[string]string
+	// Nameservers are the upstream servers that unmatched queries are
+	// forwarded to; when empty the host's resolver configuration is used
+	Nameservers []net.IP
+}
+
+type ServerOptions struct {
+	UDPPort int
+	TCPPort int
+	Address string
+	HandlerOptions
+}
+
+type Handler struct {
+	truncate     bool
+	clientConfig *dns.ClientConfig
+	clients      []*dns.Client
+	ipv6         bool
+	cnameToHost  map[string]string
+	hostToIP     map[string]net.IP
+}
+
+type Server struct {
+	udp *dns.Server
+	tcp *dns.Server
+}
+
+func (s *Server) Shutdown() {
+	if s.udp != nil {
+		_ = s.udp.Shutdown()
+	}
+	if s.tcp != nil {
+		_ = s.tcp.Shutdown()
+	}
+}
+
+// newStaticClientConfig returns a resolver configuration that only contains
+// the given servers, bypassing the host configuration
+func newStaticClientConfig(ips []net.IP) (*dns.ClientConfig, error) {
+	s := ``
+	for _, ip := range ips {
+		s += fmt.Sprintf("nameserver %s\n", ip.String())
+	}
+	r := strings.NewReader(s)
+	return dns.ClientConfigFromReader(r)
+}
+
+func NewHandler(opts HandlerOptions) (dns.Handler, error) {
+	var cc *dns.ClientConfig
+	var err error
+	if len(opts.Nameservers) > 0 {
+		// unmatched queries are forwarded to the configured servers instead
+		// of the ones from the host configuration
+		if cc, err = newStaticClientConfig(opts.Nameservers); err != nil {
+			return nil, fmt.Errorf("cannot use the configured nameservers: %w", err)
+		}
+	} else if cc, err = dns.ClientConfigFromFile("/etc/resolv.conf"); err != nil {
+		logrus.WithError(err).Warnf("cannot detect the host resolver configuration, falling back to %v", defaultFallbackIPs)
+		if cc, err = newStaticClientConfig(defaultFallbackIPs); err != nil {
+			return nil, err
+		}
+	}
+	clients := []*dns.Client{
+		{}, // UDP
+		{Net: "tcp"},
+	}
+	h := &Handler{
+		truncate:     true,
+		clientConfig: cc,
+		clients:      clients,
+		ipv6:         opts.IPv6,
+		cnameToHost:  make(map[string]string),
+		hostToIP:     make(map[string]net.IP),
+	}
+	for host, address := range opts.StaticHosts {
+		cname := dns.CanonicalName(host)
+		if ip := net.ParseIP(address); ip != nil {
+			h.hostToIP[cname] = ip
+		} else {
+			h.cnameToHost[cname] = dns.CanonicalName(address)
+		}
+	}
+	return h, nil
+}
+
+func (h *Handler) lookupStatic(name string) (net.IP, string, bool) {
+	cname := dns.CanonicalName(name)
+	// follow at most one level of static CNAME indirection
+	if target, ok := h.cnameToHost[cname]; ok {
+		ip, hasIP := h.hostToIP[target]
+		return ip, target, hasIP
+	}
+	if ip, ok := h.hostToIP[cname]; ok {
+		return ip, "", true
+	}
+	return nil, "", false
+}
+
+func (h *Handler) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	reply := dns.Msg{}
+	reply.SetReply(req)
+	reply.RecursionAvailable = true
+
+	handled := false
+	for _, q := range req.Question {
+		switch q.Qtype {
+		case dns.TypeA, dns.TypeAAAA, dns.TypeCNAME:
+			ip, target, hasIP := h.lookupStatic(q.Name)
+			if target != "" {
+				reply.Answer = append(reply.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 5},
+					Target: target,
+				})
+				handled = true
+			}
+			if !hasIP {
+				continue
+			}
+			hdr := dns.RR_Header{Name: q.Name, Class: dns.ClassINET, Ttl: 5}
+			if ipv4 := ip.To4(); ipv4 != nil {
+				if q.Qtype == dns.TypeA {
+					hdr.Rrtype = dns.TypeA
+					reply.Answer = append(reply.Answer, &dns.A{Hdr: hdr, A: ipv4})
+				}
+				handled = true
+			} else if h.ipv6 {
+				if q.Qtype == dns.TypeAAAA {
+					hdr.Rrtype = dns.TypeAAAA
+					reply.Answer = append(reply.Answer, &dns.AAAA{Hdr: hdr, AAAA: ip})
+				}
+				handled = true
+			}
+		}
+	}
+	if handled {
+		if h.truncate {
+			reply.Truncate(truncateSize)
+		}
+		if err := w.WriteMsg(&reply); err != nil {
+			logrus.WithError(err).Debug("writing dns reply")
+		}
+		return
+	}
+	h.handleDefault(w, req)
+}
+
+// handleDefault forwards the query to the upstream servers, trying UDP first
+// and falling back to TCP
+func (h *Handler) handleDefault(w dns.ResponseWriter, req *dns.Msg) {
+	for _, client := range h.clients {
+		for _, srv := range h.clientConfig.Servers {
+			addr := net.JoinHostPort(srv, h.clientConfig.Port)
+			reply, _, err := client.Exchange(req, addr)
+			if err != nil {
+				logrus.WithError(err).Debugf("forwarding query to %s", addr)
+				continue
+			}
+			if h.truncate {
+				reply.Truncate(truncateSize)
+			}
+			if err := w.WriteMsg(reply); err != nil {
+				logrus.WithError(err).Debug("writing dns reply")
+			}
+			return
+		}
+	}
+	reply := dns.Msg{}
+	reply.SetRcode(req, dns.RcodeServerFailure)
+	if err := w.WriteMsg(&reply); err != nil {
+		logrus.WithError(err).Debug("writing dns reply")
+	}
+}
+
+func Start(opts ServerOptions) (*Server, error) {
+	h, err := NewHandler(opts.HandlerOptions)
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{}
+	if opts.UDPPort > 0 {
+		addr := fmt.Sprintf("%s:%d", opts.Address, opts.UDPPort)
+		s := &dns.Server{Net: "udp", Addr: addr, Handler: h}
+		server.udp = s
+		go func() {
+			if e := s.ListenAndServe(); e != nil {
+				logrus.WithError(e).Errorf("udp dns server on %s", addr)
+			}
+		}()
+	}
+	if opts.TCPPort > 0 {
+		addr := fmt.Sprintf("%s:%d", opts.Address, opts.TCPPort)
+		s := &dns.Server{Net: "tcp", Addr: addr, Handler: h}
+		server.tcp = s
+		go func() {
+			if e := s.ListenAndServe(); e != nil {
+				logrus.WithError(e).Errorf("tcp dns server on %s", addr)
+			}
+		}()
+	}
+	return server, nil
+}
@@ -144,6 +144,12 @@ func New(instName string, stdout io.Writer, signalCh chan os.Signal, opts ...Opt
 	if err != nil {
 		return nil, err
 	}
+	// additional `ssh.identityFiles` keys are tried after the per-instance key
+	identityOpts, err := limayaml.SSHIdentityFileEntries(y)
+	if err != nil {
+		return nil, err
+	}
+	sshOpts = append(sshOpts, identityOpts...)
 	if err = writeSSHConfigFile(inst, inst.SSHAddress, sshLocalPort, sshOpts); err != nil {
 		return nil, err
 	}
@@ -317,6 +323,9 @@ func (a *HostAgent) Run(ctx context.Context) error {
 			HandlerOptions: dns.HandlerOptions{
 				IPv6:        *a.y.HostResolver.IPv6,
 				StaticHosts: hosts,
+				// unmatched queries are forwarded to these servers instead of
+				// the ones from the host configuration
+				Nameservers: a.y.HostResolver.Nameservers,
 			},
 		}
 		dnsServer, err := dns.Start(srvOpts)
@@ -326,6 +335,18 @@ func (a *HostAgent) Run(ctx context.Context) error {
 		defer dnsServer.Shutdown()
 	}
 
+	if *a.y.HostCgroup.Enabled {
+		cpus, memoryBytes, err := limayaml.HostCgroupLimits(a.y)
+		if err != nil {
+			return err
+		}
+		// the driver spawns the VM process as a child, so joining the cgroup
+		// here puts the VM under the limits as well
+		if err := applyHostCgroupLimits(a.instName, cpus, memoryBytes); err != nil {
+			return fmt.Errorf("cannot apply the host cgroup limits: %w", err)
+		}
+	}
+
 	errCh, err := a.driver.Start(ctx)
 	if err != nil {
 		return err
@@ -352,9 +373,14 @@ func (a *HostAgent) Run(ctx context.Context) error {
 		}
 		vncport := strconv.Itoa(5900 + n)
 		vncpwdfile := filepath.Join(a.instDir, filenames.VNCPasswordFile)
-		vncpasswd, err := generatePassword(8)
-		if err != nil {
-			return err
+		var vncpasswd string
+		if a.y.Video.VNC.Password != nil && *a.y.Video.VNC.Password != "" {
+			vncpasswd = *a.y.Video.VNC.Password
+		} else {
+			vncpasswd, err = generatePassword(8)
+			if err != nil {
+				return err
+			}
 		}
 		if err := a.driver.ChangeDisplayPassword(ctx, vncpasswd); err != nil {
 			return err
@@ -469,6 +495,14 @@ sudo chown -R "${USER}" /run/host-services`
 			errs = append(errs, fmt.Errorf("stdout=%q, stderr=%q: %w", stdout, stderr, err))
 		}
 	}
+	if script := perInterfaceDNSScript(a.y.Networks); script != "" {
+		desc := "applying the per-interface DNS overrides from `networks[].dns`"
+		stdout, stderr, err := ssh.ExecuteScript(a.instSSHAddress, a.sshLocalPort, a.sshConfig, script, desc)
+		logrus.Debugf("stdout=%q, stderr=%q, err=%v", stdout, stderr, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stdout=%q, stderr=%q: %w", stdout, stderr, err))
+		}
+	}
 	if *a.y.MountType == limayaml.REVSSHFS && !*a.y.Plain {
 		mounts, err := a.setupMounts()
 		if err != nil {
@@ -704,6 +738,27 @@ func executeSSH(ctx context.Context, sshConfig *ssh.SSHConfig, port int, command
 	return nil
 }
 
+// perInterfaceDNSScript returns a script that applies the `networks[].dns`
+// overrides to the guest's per-link resolver configuration via
+// systemd-resolved; the empty string is returned when no network declares one.
+func perInterfaceDNSScript(networks []limayaml.Network) string {
+	var cmds []string
+	for _, nw := range networks {
+		if nw.Interface == "" || len(nw.DNS) == 0 {
+			continue
+		}
+		addrs := make([]string, 0, len(nw.DNS))
+		for _, ip := range nw.DNS {
+			addrs = append(addrs, ip.String())
+		}
+		cmds = append(cmds, fmt.Sprintf("sudo resolvectl dns %s %s", nw.Interface, strings.Join(addrs, " ")))
+	}
+	if len(cmds) == 0 {
+		return ""
+	}
+	return "#!/bin/bash\nset -eux -o pipefail\n" + strings.Join(cmds, "\n")
+}
+
 func forwardSSH(ctx context.Context, sshConfig *ssh.SSHConfig, port int, local, remote, verb string, reverse bool) error {
 	args := sshConfig.Args()
 	args = append(args,
@@ -4,10 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/lima-vm/lima/pkg/limayaml"
 	"github.com/lima-vm/lima/pkg/localpathutil"
 	"github.com/lima-vm/sshocker/pkg/reversesshfs"
+	"github.com/lima-vm/sshocker/pkg/ssh"
 	"github.com/sirupsen/logrus"
 )
 
@@ -76,6 +80,31 @@ func (a *HostAgent) setupMount(m limayaml.Mount) (*mount, error) {
 		}
 	}
 
+	// Expose the requested subpaths read-only inside the guest by stacking a
+	// read-only bind mount on top of each of them; this works for writable
+	// mounts as well, e.g. a writable home dir with a read-only ~/.ssh
+	for _, ro := range m.ReadOnlyPaths {
+		roLocation, err := localpathutil.Expand(ro)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(location, roLocation)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("read-only path %q must be under the mount location %q", ro, location)
+		}
+		guestPath := path.Join(mountPoint, filepath.ToSlash(rel))
+		script := fmt.Sprintf(`#!/bin/sh
+set -eu
+sudo mount --bind %q %q
+sudo mount -o remount,bind,ro %q
+`, guestPath, guestPath, guestPath)
+		description := fmt.Sprintf("read-only bind for %q", guestPath)
+		stdout, stderr, err := ssh.ExecuteScript(a.instSSHAddress, a.sshLocalPort, a.sshConfig, script, description)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make %q read-only: stdout=%q, stderr=%q: %w", guestPath, stdout, stderr, err)
+		}
+	}
+
 	res := &mount{
 		close: func() error {
 			logrus.Infof("Unmounting %q", location)
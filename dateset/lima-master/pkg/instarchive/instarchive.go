@@ -0,0 +1,153 @@
+// Package instarchive bundles an instance directory into a single portable
+// tar archive, and reconstructs an instance directory from such an archive.
+package instarchive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lima-vm/lima/pkg/store/filenames"
+	"github.com/lima-vm/lima/pkg/yqutil"
+)
+
+// skipOnExport returns true for host-specific runtime artifacts (sockets,
+// logs, PID files) that are recreated when the instance is started.
+func skipOnExport(base string) bool {
+	if strings.HasSuffix(base, ".sock") || strings.HasSuffix(base, ".log") || strings.HasSuffix(base, ".pid") {
+		return true
+	}
+	switch base {
+	case filenames.SocketDir, filenames.VNCDisplayFile, filenames.VNCPasswordFile:
+		return true
+	}
+	return false
+}
+
+// Export writes the contents of the instance directory instDir as a tar
+// archive to w. Runtime artifacts such as sockets and logs are not included.
+func Export(instDir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(instDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(instDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if skipOnExport(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Sockets and other irregular files cannot be archived
+		if !fi.Mode().IsRegular() && !fi.IsDir() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Import reconstructs an instance directory at instDir from the tar archive
+// read from r. Unless preserveMAC is set, `networks[].macAddress` entries are
+// removed from the extracted lima.yaml, so that MAC addresses are regenerated
+// for the new instance directory by the existing logic.
+func Import(r io.Reader, instDir string, preserveMAC bool) error {
+	if err := os.MkdirAll(instDir, 0o700); err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	sawLimaYAML := false
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || strings.HasPrefix(name, "..") {
+			return fmt.Errorf("unexpected file path in archive: %q", hdr.Name)
+		}
+		if name == filenames.LimaYAML {
+			sawLimaYAML = true
+		}
+		path := filepath.Join(instDir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected file type %d for %q in archive", hdr.Typeflag, hdr.Name)
+		}
+	}
+	if !sawLimaYAML {
+		return fmt.Errorf("archive does not contain %q", filenames.LimaYAML)
+	}
+	if !preserveMAC {
+		yPath := filepath.Join(instDir, filenames.LimaYAML)
+		yContent, err := os.ReadFile(yPath)
+		if err != nil {
+			return err
+		}
+		yBytes, err := yqutil.EvaluateExpression("del(.networks[].macAddress)", yContent)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(yPath, yBytes, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
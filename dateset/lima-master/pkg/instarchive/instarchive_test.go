@@ -0,0 +1,68 @@
+package instarchive
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/pkg/store/filenames"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	instDir := t.TempDir()
+	limaYAML := `images:
+- location: https://example.com/image.img
+networks:
+- lima: shared
+  macAddress: "52:55:55:12:34:56"
+`
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.LimaYAML), []byte(limaYAML), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.BaseDisk), []byte("base disk content"), 0o644))
+	assert.NilError(t, os.Mkdir(filepath.Join(instDir, filenames.CIDataISODir), 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.CIDataISODir, "user-data"), []byte("#cloud-config"), 0o644))
+	// Runtime artifacts must not survive the round trip
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.HostAgentStdoutLog), []byte("log"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.HostAgentPID), []byte("42"), 0o644))
+
+	var buf bytes.Buffer
+	assert.NilError(t, Export(instDir, &buf))
+
+	// Preserving the MAC keeps lima.yaml identical
+	preserved := t.TempDir()
+	assert.NilError(t, Import(bytes.NewReader(buf.Bytes()), preserved, true))
+	y, err := os.ReadFile(filepath.Join(preserved, filenames.LimaYAML))
+	assert.NilError(t, err)
+	assert.Equal(t, limaYAML, string(y))
+	disk, err := os.ReadFile(filepath.Join(preserved, filenames.BaseDisk))
+	assert.NilError(t, err)
+	assert.Equal(t, "base disk content", string(disk))
+	userData, err := os.ReadFile(filepath.Join(preserved, filenames.CIDataISODir, "user-data"))
+	assert.NilError(t, err)
+	assert.Equal(t, "#cloud-config", string(userData))
+	_, err = os.Stat(filepath.Join(preserved, filenames.HostAgentStdoutLog))
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(preserved, filenames.HostAgentPID))
+	assert.Assert(t, os.IsNotExist(err))
+
+	// Without preserveMAC the macAddress is dropped for regeneration
+	regenerated := t.TempDir()
+	assert.NilError(t, Import(bytes.NewReader(buf.Bytes()), regenerated, false))
+	y, err = os.ReadFile(filepath.Join(regenerated, filenames.LimaYAML))
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(y), "macAddress"))
+	assert.Assert(t, strings.Contains(string(y), "lima: shared"))
+}
+
+func TestImportRequiresLimaYAML(t *testing.T) {
+	instDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, filenames.BaseDisk), []byte("base disk content"), 0o644))
+
+	var buf bytes.Buffer
+	assert.NilError(t, Export(instDir, &buf))
+	err := Import(bytes.NewReader(buf.Bytes()), t.TempDir(), false)
+	assert.ErrorContains(t, err, "lima.yaml")
+}
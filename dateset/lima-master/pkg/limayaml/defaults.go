@@ -17,6 +17,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/cpu"
 
+	"github.com/lima-vm/lima/pkg/localpathutil"
 	"github.com/lima-vm/lima/pkg/networks"
 	"github.com/lima-vm/lima/pkg/osutil"
 	"github.com/lima-vm/lima/pkg/ptr"
@@ -34,6 +35,10 @@ const (
 	Default9pCacheForRW      string = "mmap"
 
 	DefaultVirtiofsQueueSize int = 1024
+
+	// DefaultSystemProvisionTimeout bounds "system" mode provisioning scripts,
+	// which otherwise can hang instance startup indefinitely.
+	DefaultSystemProvisionTimeout string = "10m"
 )
 
 var IPv4loopback1 = net.IPv4(127, 0, 0, 1)
@@ -129,6 +134,23 @@ func defaultDiskSizeAsString() string {
 	return "100GiB"
 }
 
+// resolvePercentage resolves a trailing "%" in value (e.g. "50%") against
+// total, returning an absolute size formatted like defaultMemoryAsString().
+// Values without the suffix are returned unchanged, and so are invalid
+// percentages; Validate reports the latter.
+func resolvePercentage(value string, total uint64) string {
+	s, ok := strings.CutSuffix(value, "%")
+	if !ok {
+		return value
+	}
+	percent, err := strconv.ParseFloat(s, 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		logrus.Warnf("percentage %q must be a number between 1 and 100", value)
+		return value
+	}
+	return units.BytesSize(percent / 100 * float64(total))
+}
+
 func defaultGuestInstallPrefix() string {
 	return "/usr/local"
 }
@@ -211,24 +233,25 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		}
 	}
 	var overrideCPUType bool
-	for k, v := range d.CPUType {
-		if len(v) > 0 {
+	mergeCPUType := func(m map[Arch]string) {
+		// The wildcard entry seeds every arch first, so that specific-arch
+		// entries in the same map still override it
+		if v, ok := m["*"]; ok && len(v) > 0 {
 			overrideCPUType = true
-			cpuType[k] = v
-		}
-	}
-	for k, v := range y.CPUType {
-		if len(v) > 0 {
-			overrideCPUType = true
-			cpuType[k] = v
+			for arch := range cpuType {
+				cpuType[arch] = v
+			}
 		}
-	}
-	for k, v := range o.CPUType {
-		if len(v) > 0 {
-			overrideCPUType = true
-			cpuType[k] = v
+		for k, v := range m {
+			if k != "*" && len(v) > 0 {
+				overrideCPUType = true
+				cpuType[k] = v
+			}
 		}
 	}
+	mergeCPUType(d.CPUType)
+	mergeCPUType(y.CPUType)
+	mergeCPUType(o.CPUType)
 	if *y.VMType == QEMU || overrideCPUType {
 		y.CPUType = cpuType
 	}
@@ -252,6 +275,9 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 	if y.Memory == nil || *y.Memory == "" {
 		y.Memory = ptr.Of(defaultMemoryAsString())
 	}
+	// A trailing "%" means a percentage of the host's total memory, e.g.
+	// "50%" gives half of the host RAM like the built-in default does
+	y.Memory = ptr.Of(resolvePercentage(*y.Memory, memory.TotalMemory()))
 
 	if y.Disk == nil {
 		y.Disk = d.Disk
@@ -262,6 +288,15 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 	if y.Disk == nil || *y.Disk == "" {
 		y.Disk = ptr.Of(defaultDiskSizeAsString())
 	}
+	if strings.HasSuffix(*y.Disk, "%") {
+		// A trailing "%" means a percentage of the disk hosting the instance directory
+		if total, err := hostDiskSize(instDir); err == nil {
+			y.Disk = ptr.Of(resolvePercentage(*y.Disk, total))
+		} else {
+			// Keep the percentage so Validate reports it
+			logrus.WithError(err).Warnf("Couldn't determine the size of the disk hosting %q", instDir)
+		}
+	}
 
 	y.AdditionalDisks = append(append(o.AdditionalDisks, y.AdditionalDisks...), d.AdditionalDisks...)
 
@@ -294,6 +329,13 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 	if (y.Video.VNC.Display == nil || *y.Video.VNC.Display == "") && *y.VMType == QEMU {
 		y.Video.VNC.Display = ptr.Of("127.0.0.1:0,to=9")
 	}
+	// no default password; when it stays nil a random one is generated on start
+	if y.Video.VNC.Password == nil {
+		y.Video.VNC.Password = d.Video.VNC.Password
+	}
+	if o.Video.VNC.Password != nil {
+		y.Video.VNC.Password = o.Video.VNC.Password
+	}
 
 	if y.Firmware.LegacyBIOS == nil {
 		y.Firmware.LegacyBIOS = d.Firmware.LegacyBIOS
@@ -305,6 +347,22 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		y.Firmware.LegacyBIOS = ptr.Of(false)
 	}
 
+	// No builtin default: nil leaves the decision to the firmware
+	if y.Firmware.SecureBoot == nil {
+		y.Firmware.SecureBoot = d.Firmware.SecureBoot
+	}
+	if o.Firmware.SecureBoot != nil {
+		y.Firmware.SecureBoot = o.Firmware.SecureBoot
+	}
+
+	// No builtin default: the auto mode stays off unless configured
+	if y.Firmware.LegacyBIOSAuto == nil {
+		y.Firmware.LegacyBIOSAuto = d.Firmware.LegacyBIOSAuto
+	}
+	if o.Firmware.LegacyBIOSAuto != nil {
+		y.Firmware.LegacyBIOSAuto = o.Firmware.LegacyBIOSAuto
+	}
+
 	y.Firmware.Images = append(append(o.Firmware.Images, y.Firmware.Images...), d.Firmware.Images...)
 	for i := range y.Firmware.Images {
 		f := &y.Firmware.Images[i]
@@ -373,6 +431,9 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		y.SSH.ForwardX11Trusted = ptr.Of(false)
 	}
 
+	// o takes priority, so its identity files are tried first
+	y.SSH.IdentityFiles = unique(append(append(o.SSH.IdentityFiles, y.SSH.IdentityFiles...), d.SSH.IdentityFiles...))
+
 	hosts := make(map[string]string)
 	// Values can be either names or IP addresses. Name values are canonicalized in the hostResolver.
 	for k, v := range d.HostResolver.Hosts {
@@ -395,6 +456,27 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		if provision.Mode == ProvisionModeDependency && provision.SkipDefaultDependencyResolution == nil {
 			provision.SkipDefaultDependencyResolution = ptr.Of(false)
 		}
+		if provision.Mode == ProvisionModeSystem && provision.Timeout == nil {
+			provision.Timeout = ptr.Of(DefaultSystemProvisionTimeout)
+		}
+		if provision.File != "" {
+			if provision.Script != "" {
+				// Leave both set; Validate reports them as mutually exclusive
+				logrus.Warnf("Both provision[%d].script and provision[%d].file are set; ignoring the file", i, i)
+			} else {
+				scriptFile := provision.File
+				if !filepath.IsAbs(scriptFile) {
+					scriptFile = filepath.Join(instDir, scriptFile)
+				}
+				if b, err := os.ReadFile(scriptFile); err == nil {
+					provision.Script = string(b)
+					provision.File = ""
+				} else {
+					// Keep File set so Validate reports the unreadable file
+					logrus.WithError(err).Warnf("Couldn't read provisioning script file %q", provision.File)
+				}
+			}
+		}
 		if out, err := executeGuestTemplate(provision.Script, instDir); err == nil {
 			provision.Script = out.String()
 		} else {
@@ -422,6 +504,15 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		y.UpgradePackages = ptr.Of(false)
 	}
 
+	if y.UpgradePackagesSchedule == nil {
+		y.UpgradePackagesSchedule = d.UpgradePackagesSchedule
+	}
+	if o.UpgradePackagesSchedule != nil {
+		y.UpgradePackagesSchedule = o.UpgradePackagesSchedule
+	}
+	// y.UpgradePackagesSchedule remains unset unless explicitly configured;
+	// no guest timer is installed in that case
+
 	if y.Containerd.System == nil {
 		y.Containerd.System = d.Containerd.System
 	}
@@ -494,6 +585,14 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		y.HostResolver.IPv6 = ptr.Of(false)
 	}
 
+	// Note: like `dns`, nameserver lists are not combined; the highest priority setting is picked
+	if len(y.HostResolver.Nameservers) == 0 {
+		y.HostResolver.Nameservers = d.HostResolver.Nameservers
+	}
+	if len(o.HostResolver.Nameservers) > 0 {
+		y.HostResolver.Nameservers = o.HostResolver.Nameservers
+	}
+
 	if y.PropagateProxyEnv == nil {
 		y.PropagateProxyEnv = d.PropagateProxyEnv
 	}
@@ -544,6 +643,9 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 			if nw.MACAddress != "" {
 				networks[i].MACAddress = nw.MACAddress
 			}
+			if len(nw.DNS) > 0 {
+				networks[i].DNS = nw.DNS
+			}
 		} else {
 			// unnamed network definitions are not combined/overwritten
 			if nw.Interface != "" {
@@ -622,6 +724,10 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 			if mount.Writable != nil {
 				mounts[i].Writable = mount.Writable
 			}
+			// ReadOnlyPaths are uniquely appended like CACertificates, so a
+			// profile can protect additional subpaths without dropping the
+			// ones from the base config
+			mounts[i].ReadOnlyPaths = unique(append(mounts[i].ReadOnlyPaths, mount.ReadOnlyPaths...))
 			if mount.MountPoint != "" {
 				mounts[i].MountPoint = mount.MountPoint
 			}
@@ -688,6 +794,13 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 	for k, v := range o.Env {
 		env[k] = v
 	}
+	for k, v := range env {
+		if out, err := executeGuestTemplate(v, instDir); err == nil {
+			env[k] = out.String()
+		} else {
+			logrus.WithError(err).Warnf("Couldn't process env variable %q=%q as a template", k, v)
+		}
+	}
 	y.Env = env
 
 	if y.CACertificates.RemoveDefaults == nil {
@@ -706,6 +819,18 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 	caCerts := unique(append(append(d.CACertificates.Certs, y.CACertificates.Certs...), o.CACertificates.Certs...))
 	y.CACertificates.Certs = caCerts
 
+	caTrustStores := unique(append(append(d.CACertificates.TrustStores, y.CACertificates.TrustStores...), o.CACertificates.TrustStores...))
+	y.CACertificates.TrustStores = caTrustStores
+	for _, store := range caTrustStores {
+		pem, err := readCATrustStore(store)
+		if err != nil {
+			logrus.WithError(err).Warnf("Couldn't read trust store %q", store)
+			continue
+		}
+		y.CACertificates.Certs = append(y.CACertificates.Certs, pem)
+	}
+	y.CACertificates.Certs = unique(y.CACertificates.Certs)
+
 	if runtime.GOOS == "darwin" && IsNativeArch(AARCH64) {
 		if y.Rosetta.Enabled == nil {
 			y.Rosetta.Enabled = d.Rosetta.Enabled
@@ -740,6 +865,25 @@ func FillDefault(y, d, o *LimaYAML, filePath string) {
 		y.Plain = ptr.Of(false)
 	}
 
+	if y.HostCgroup.Enabled == nil {
+		y.HostCgroup.Enabled = d.HostCgroup.Enabled
+	}
+	if o.HostCgroup.Enabled != nil {
+		y.HostCgroup.Enabled = o.HostCgroup.Enabled
+	}
+	if y.HostCgroup.Enabled == nil {
+		y.HostCgroup.Enabled = ptr.Of(false)
+	}
+	if y.HostCgroup.Headroom == nil {
+		y.HostCgroup.Headroom = d.HostCgroup.Headroom
+	}
+	if o.HostCgroup.Headroom != nil {
+		y.HostCgroup.Headroom = o.HostCgroup.Headroom
+	}
+	if y.HostCgroup.Headroom == nil {
+		y.HostCgroup.Headroom = ptr.Of(0.1)
+	}
+
 	fixUpForPlainMode(y)
 }
 
@@ -981,6 +1125,109 @@ func IsNativeArch(arch Arch) bool {
 	return nativeX8664 || nativeAARCH64 || nativeARMV7L || nativeRISCV64
 }
 
+// imageHasUEFIBoot reports whether the image at the given local path contains
+// an EFI system partition, by looking for the ESP type GUID in the GPT entry
+// area. It is a variable so tests can stub the inspection.
+var imageHasUEFIBoot = func(imagePath string) (bool, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	// GPT partition entries normally occupy LBA 2-33
+	buf := make([]byte, 34*512)
+	n, err := f.Read(buf)
+	if n == 0 && err != nil {
+		return false, err
+	}
+	// EFI system partition type GUID (C12A7328-F81F-11D2-BA4B-00A0C93EC93B)
+	// in its on-disk mixed-endian encoding
+	espGUID := []byte{0x28, 0x73, 0x2a, 0xc1, 0x1f, 0xf8, 0xd2, 0x11, 0xba, 0x4b, 0x00, 0xa0, 0xc9, 0x3e, 0xc9, 0x3b}
+	return bytes.Contains(buf[:n], espGUID), nil
+}
+
+// ResolveLegacyBIOS returns whether the instance should boot with legacy BIOS.
+// With `firmware.legacyBIOSAuto` enabled the image is inspected for a UEFI ESP
+// and legacy BIOS is chosen when none is present; otherwise the explicit
+// `firmware.legacyBIOS` setting is used unchanged.
+func ResolveLegacyBIOS(y *LimaYAML, imagePath string) bool {
+	explicit := y.Firmware.LegacyBIOS != nil && *y.Firmware.LegacyBIOS
+	if y.Firmware.LegacyBIOSAuto == nil || !*y.Firmware.LegacyBIOSAuto {
+		return explicit
+	}
+	hasUEFI, err := imageHasUEFIBoot(imagePath)
+	if err != nil {
+		logrus.WithError(err).Warnf("Couldn't inspect image %q for a UEFI ESP; keeping legacyBIOS=%t", imagePath, explicit)
+		return explicit
+	}
+	if !hasUEFI {
+		logrus.Infof("Image %q has no UEFI ESP; falling back to legacy BIOS", imagePath)
+		return true
+	}
+	logrus.Debugf("Image %q has a UEFI ESP; booting with UEFI", imagePath)
+	return false
+}
+
+// SSHIdentityFileEntries expands `ssh.identityFiles` for the host and returns
+// the `IdentityFile` options to be appended to the composed SSH options.
+func SSHIdentityFileEntries(y *LimaYAML) ([]string, error) {
+	var entries []string
+	for _, f := range y.SSH.IdentityFiles {
+		expanded, err := localpathutil.Expand(f)
+		if err != nil {
+			return nil, fmt.Errorf("ssh.identityFiles: invalid path %q: %w", f, err)
+		}
+		entries = append(entries, fmt.Sprintf("IdentityFile=%q", expanded))
+	}
+	return entries, nil
+}
+
+// HostCgroupLimits returns the CPU and memory limits to apply to the host
+// cgroup of the QEMU process: the instance's declared cpus/memory scaled up by
+// the configured headroom factor. It expects y to have passed FillDefault.
+func HostCgroupLimits(y *LimaYAML) (cpus float64, memoryBytes int64, err error) {
+	memory, err := units.RAMInBytes(*y.Memory)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing `memory`: %w", err)
+	}
+	headroom := 1.0
+	if y.HostCgroup.Headroom != nil {
+		headroom += *y.HostCgroup.Headroom
+	}
+	cpus = float64(*y.CPUs) * headroom
+	memoryBytes = int64(float64(memory) * headroom)
+	return cpus, memoryBytes, nil
+}
+
+// caTrustStorePaths maps well-known trust store names that can be referenced
+// in `caCerts.trustStores` to candidate bundle locations on the host.
+var caTrustStorePaths = map[string][]string{
+	"system": {
+		"/etc/ssl/certs/ca-certificates.crt", // Debian/Ubuntu/Alpine
+		"/etc/pki/tls/certs/ca-bundle.crt",   // Fedora/RHEL
+		"/etc/ssl/cert.pem",                  // macOS/OpenBSD
+	},
+	"mozilla": {
+		"/etc/ssl/certs/ca-certificates.crt", // derived from the Mozilla bundle on most distros
+		"/usr/local/share/ca-certificates/mozilla.crt",
+	},
+}
+
+// readCATrustStore returns the PEM contents of a well-known host trust store.
+// It is a variable so tests can stub out the host file system.
+var readCATrustStore = func(name string) (string, error) {
+	paths, ok := caTrustStorePaths[name]
+	if !ok {
+		return "", fmt.Errorf("unknown trust store %q", name)
+	}
+	for _, p := range paths {
+		if b, err := os.ReadFile(p); err == nil {
+			return string(b), nil
+		}
+	}
+	return "", fmt.Errorf("no readable bundle found on the host for trust store %q", name)
+}
+
 func unique(s []string) []string {
 	keys := make(map[string]bool)
 	list := []string{}
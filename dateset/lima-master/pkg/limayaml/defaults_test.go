@@ -6,14 +6,17 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/docker/go-units"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/lima-vm/lima/pkg/osutil"
 	"github.com/lima-vm/lima/pkg/ptr"
 	"github.com/lima-vm/lima/pkg/store/dirnames"
 	"github.com/lima-vm/lima/pkg/store/filenames"
+	"github.com/pbnjay/memory"
 	"gotest.tools/v3/assert"
 )
 
@@ -107,6 +110,10 @@ func TestFillDefault(t *testing.T) {
 			RemoveDefaults: ptr.Of(false),
 		},
 		Plain: ptr.Of(false),
+		HostCgroup: HostCgroup{
+			Enabled:  ptr.Of(false),
+			Headroom: ptr.Of(0.1),
+		},
 	}
 	if IsAccelOS() {
 		if HasHostCPU() {
@@ -230,6 +237,7 @@ func TestFillDefault(t *testing.T) {
 
 	expect.Provision = y.Provision
 	expect.Provision[0].Mode = ProvisionModeSystem
+	expect.Provision[0].Timeout = ptr.Of(DefaultSystemProvisionTimeout)
 
 	expect.Probes = y.Probes
 	expect.Probes[0].Mode = ProbeModeReadiness
@@ -446,6 +454,10 @@ func TestFillDefault(t *testing.T) {
 		}
 	}
 	expect.Plain = ptr.Of(false)
+	expect.HostCgroup = HostCgroup{
+		Enabled:  ptr.Of(false),
+		Headroom: ptr.Of(0.1),
+	}
 
 	y = LimaYAML{}
 	FillDefault(&y, &d, &LimaYAML{}, filePath)
@@ -618,6 +630,7 @@ func TestFillDefault(t *testing.T) {
 	expect = o
 
 	expect.Provision = append(append(o.Provision, y.Provision...), d.Provision...)
+	expect.Provision[0].Timeout = ptr.Of(DefaultSystemProvisionTimeout)
 	expect.Probes = append(append(o.Probes, y.Probes...), d.Probes...)
 	expect.PortForwards = append(append(o.PortForwards, y.PortForwards...), d.PortForwards...)
 	expect.CopyToHost = append(append(o.CopyToHost, y.CopyToHost...), d.CopyToHost...)
@@ -665,7 +678,279 @@ func TestFillDefault(t *testing.T) {
 		BinFmt:  ptr.Of(false),
 	}
 	expect.Plain = ptr.Of(false)
+	expect.HostCgroup = HostCgroup{
+		Enabled:  ptr.Of(false),
+		Headroom: ptr.Of(0.1),
+	}
 
 	FillDefault(&y, &d, &o, filePath)
 	assert.DeepEqual(t, &y, &expect, opts...)
 }
+
+func TestFillDefaultUpgradePackagesSchedule(t *testing.T) {
+	var d, y, o LimaYAML
+
+	// FillDefault must leave the schedule unset unless explicitly configured
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Assert(t, y.UpgradePackagesSchedule == nil)
+
+	// Values from the default and override files are inherited as usual
+	d = LimaYAML{UpgradePackagesSchedule: ptr.Of(UpgradePackagesScheduleWeekly)}
+	y = LimaYAML{}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Equal(t, UpgradePackagesScheduleWeekly, *y.UpgradePackagesSchedule)
+	assert.NilError(t, Validate(y, false))
+
+	o = LimaYAML{UpgradePackagesSchedule: ptr.Of(UpgradePackagesScheduleDaily)}
+	y = LimaYAML{}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Equal(t, UpgradePackagesScheduleDaily, *y.UpgradePackagesSchedule)
+
+	// Unknown schedule values are rejected
+	y = LimaYAML{UpgradePackagesSchedule: ptr.Of("fortnightly")}
+	FillDefault(&y, &LimaYAML{}, &LimaYAML{}, "lima.yaml")
+	assert.ErrorContains(t, Validate(y, false), "upgradePackagesSchedule")
+}
+
+func TestFillDefaultCACertTrustStores(t *testing.T) {
+	stubPEM := "-----BEGIN CERTIFICATE-----\nSTUBBED-HOST-CA-CERT\n-----END CERTIFICATE-----\n"
+	savedReadCATrustStore := readCATrustStore
+	readCATrustStore = func(name string) (string, error) {
+		if name != "system" {
+			return "", fmt.Errorf("unknown trust store %q", name)
+		}
+		return stubPEM, nil
+	}
+	defer func() { readCATrustStore = savedReadCATrustStore }()
+
+	// The "system" reference expands to the host trust store contents
+	var d, o LimaYAML
+	y := LimaYAML{
+		CACertificates: CACertificates{
+			TrustStores: []string{"system"},
+		},
+	}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.DeepEqual(t, []string{"system"}, y.CACertificates.TrustStores)
+	assert.DeepEqual(t, []string{stubPEM}, y.CACertificates.Certs)
+	assert.NilError(t, Validate(y, false))
+
+	// Unknown trust store names are rejected by Validate
+	y = LimaYAML{
+		CACertificates: CACertificates{
+			TrustStores: []string{"acme-corp"},
+		},
+	}
+	FillDefault(&y, &LimaYAML{}, &LimaYAML{}, "lima.yaml")
+	assert.ErrorContains(t, Validate(y, false), "caCerts.trustStores")
+}
+
+func TestHostCgroupLimits(t *testing.T) {
+	y := LimaYAML{
+		CPUs:   ptr.Of(4),
+		Memory: ptr.Of("4GiB"),
+		HostCgroup: HostCgroup{
+			Enabled:  ptr.Of(true),
+			Headroom: ptr.Of(0.25),
+		},
+	}
+	cpus, memory, err := HostCgroupLimits(&y)
+	assert.NilError(t, err)
+	assert.Equal(t, 5.0, cpus)
+	assert.Equal(t, int64(float64(4<<30)*1.25), memory)
+
+	// Without a headroom the limits match the declared resources
+	y.HostCgroup.Headroom = nil
+	cpus, memory, err = HostCgroupLimits(&y)
+	assert.NilError(t, err)
+	assert.Equal(t, 4.0, cpus)
+	assert.Equal(t, int64(4<<30), memory)
+
+	// A negative headroom must be rejected by Validate
+	y.HostCgroup.Headroom = ptr.Of(-0.5)
+	FillDefault(&y, &LimaYAML{}, &LimaYAML{}, "lima.yaml")
+	assert.ErrorContains(t, Validate(y, false), "hostCgroup.headroom")
+}
+
+func TestResolveLegacyBIOS(t *testing.T) {
+	savedInspector := imageHasUEFIBoot
+	hasUEFI := false
+	imageHasUEFIBoot = func(_ string) (bool, error) {
+		return hasUEFI, nil
+	}
+	defer func() { imageHasUEFIBoot = savedInspector }()
+
+	// Explicit true/false keep working when the auto mode is off
+	y := LimaYAML{Firmware: Firmware{LegacyBIOS: ptr.Of(true)}}
+	assert.Equal(t, true, ResolveLegacyBIOS(&y, "disk.img"))
+	y.Firmware.LegacyBIOS = ptr.Of(false)
+	assert.Equal(t, false, ResolveLegacyBIOS(&y, "disk.img"))
+
+	// Auto mode falls back to BIOS for an image without a UEFI ESP
+	y.Firmware.LegacyBIOSAuto = ptr.Of(true)
+	assert.Equal(t, true, ResolveLegacyBIOS(&y, "disk.img"))
+
+	// ... and keeps UEFI when the image has one
+	hasUEFI = true
+	assert.Equal(t, false, ResolveLegacyBIOS(&y, "disk.img"))
+}
+
+func TestFillDefaultNetworkDNS(t *testing.T) {
+	var d, o LimaYAML
+	y := LimaYAML{
+		Networks: []Network{
+			{Interface: "vpn0", Socket: "/run/vpn0.sock", DNS: []net.IP{net.IPv4(10, 0, 0, 53)}},
+			{Interface: "lima0", Socket: "/run/lima0.sock"},
+		},
+	}
+	// The override wins in the interface-keyed merge
+	o.Networks = []Network{{Interface: "vpn0", DNS: []net.IP{net.IPv4(10, 0, 0, 2)}}}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.DeepEqual(t, []net.IP{net.IPv4(10, 0, 0, 2)}, y.Networks[0].DNS)
+	assert.Assert(t, y.Networks[1].DNS == nil)
+	assert.NilError(t, Validate(y, false))
+
+	// A resolver that isn't a valid IP address is rejected
+	y.Networks[1].DNS = []net.IP{net.IP([]byte{10, 0})}
+	assert.ErrorContains(t, Validate(y, false), "networks[1].dns[0]")
+}
+
+func TestFillDefaultSSHIdentityFiles(t *testing.T) {
+	var d, o LimaYAML
+	y := LimaYAML{SSH: SSH{IdentityFiles: []string{"~/.ssh/id_ed25519"}}}
+	o.SSH.IdentityFiles = []string{"/etc/lima/override_key"}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	// The override's keys are tried first
+	assert.DeepEqual(t, []string{"/etc/lima/override_key", "~/.ssh/id_ed25519"}, y.SSH.IdentityFiles)
+
+	// The composed SSH options contain the expanded entries
+	entries, err := SSHIdentityFileEntries(&y)
+	assert.NilError(t, err)
+	homeDir, err := os.UserHomeDir()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{
+		fmt.Sprintf("IdentityFile=%q", "/etc/lima/override_key"),
+		fmt.Sprintf("IdentityFile=%q", filepath.Join(homeDir, ".ssh", "id_ed25519")),
+	}, entries)
+
+	// Paths like "~foo" cannot be expanded
+	y.SSH.IdentityFiles = []string{"~nobody/key"}
+	assert.ErrorContains(t, Validate(y, false), "ssh.identityFiles[0]")
+}
+
+func TestFillDefaultProvisionFile(t *testing.T) {
+	instDir := t.TempDir()
+	filePath := filepath.Join(instDir, "lima.yaml")
+	script := "#!/bin/sh\necho {{.Home}}\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(instDir, "provision.sh"), []byte(script), 0o644))
+
+	var d, o LimaYAML
+	y := LimaYAML{Provision: []Provision{{File: "provision.sh"}}}
+	FillDefault(&y, &d, &o, filePath)
+	// The file body is loaded into Script and still goes through the guest template
+	assert.Assert(t, strings.HasPrefix(y.Provision[0].Script, "#!/bin/sh\necho /home/"))
+	assert.Equal(t, "", y.Provision[0].File)
+	assert.NilError(t, Validate(y, false))
+
+	// Script and File are mutually exclusive
+	y = LimaYAML{Provision: []Provision{{Script: "#!/bin/sh\ntrue", File: "provision.sh"}}}
+	FillDefault(&y, &d, &o, filePath)
+	assert.ErrorContains(t, Validate(y, false), "mutually exclusive")
+
+	// A missing file survives FillDefault and is reported by Validate
+	y = LimaYAML{Provision: []Provision{{File: "missing.sh"}}}
+	FillDefault(&y, &d, &o, filePath)
+	assert.ErrorContains(t, Validate(y, false), "could not be read")
+}
+
+func TestFillDefaultSizePercentage(t *testing.T) {
+	instDir := t.TempDir()
+	filePath := filepath.Join(instDir, "lima.yaml")
+
+	var d, o LimaYAML
+	y := LimaYAML{Memory: ptr.Of("50%"), Disk: ptr.Of("10%")}
+	FillDefault(&y, &d, &o, filePath)
+	// Half of the host memory, formatted like the built-in default
+	assert.Equal(t, units.BytesSize(float64(memory.TotalMemory())/2), *y.Memory)
+	// The disk percentage resolves against the disk hosting the instance dir
+	assert.Assert(t, !strings.HasSuffix(*y.Disk, "%"))
+	assert.NilError(t, Validate(y, false))
+
+	// Invalid percentages survive FillDefault and are reported by Validate
+	y = LimaYAML{Memory: ptr.Of("150%")}
+	FillDefault(&y, &d, &o, filePath)
+	assert.ErrorContains(t, Validate(y, false), "field `memory` has an invalid percentage")
+
+	y = LimaYAML{Disk: ptr.Of("many%")}
+	FillDefault(&y, &d, &o, filePath)
+	assert.ErrorContains(t, Validate(y, false), "field `disk` has an invalid percentage")
+}
+
+func TestFillDefaultFirmwareSecureBoot(t *testing.T) {
+	var d, y, o LimaYAML
+	FillDefault(&y, &d, &o, "lima.yaml")
+	// nil means the firmware default, so FillDefault must not materialize it
+	assert.Assert(t, y.Firmware.SecureBoot == nil)
+
+	y = LimaYAML{Firmware: Firmware{SecureBoot: ptr.Of(true)}}
+	o = LimaYAML{Firmware: Firmware{SecureBoot: ptr.Of(false)}}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Equal(t, false, *y.Firmware.SecureBoot)
+}
+
+func TestFillDefaultCPUTypeWildcard(t *testing.T) {
+	var d, o LimaYAML
+	y := LimaYAML{
+		VMType:  ptr.Of("vz"),
+		CPUType: map[Arch]string{"*": "max"},
+	}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	// The wildcard applies to every arch and is retained for non-QEMU too
+	for _, arch := range []Arch{AARCH64, ARMV7L, X8664, RISCV64} {
+		assert.Equal(t, "max", y.CPUType[arch])
+	}
+
+	// A specific-arch entry overrides the wildcard, regardless of map order
+	y = LimaYAML{
+		CPUType: map[Arch]string{"*": "max", AARCH64: "cortex-a72"},
+	}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Equal(t, "cortex-a72", y.CPUType[AARCH64])
+	assert.Equal(t, "max", y.CPUType[X8664])
+
+	// An override wildcard beats the config's specific-arch entries
+	y = LimaYAML{
+		CPUType: map[Arch]string{AARCH64: "cortex-a72"},
+	}
+	o = LimaYAML{
+		CPUType: map[Arch]string{"*": "host"},
+	}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.Equal(t, "host", y.CPUType[AARCH64])
+}
+
+func TestFillDefaultMountReadOnlyPaths(t *testing.T) {
+	var d, o LimaYAML
+	y := LimaYAML{Mounts: []Mount{{
+		Location:      "/tmp/proj",
+		Writable:      ptr.Of(true),
+		ReadOnlyPaths: []string{"/tmp/proj/.git"},
+	}}}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.DeepEqual(t, []string{"/tmp/proj/.git"}, y.Mounts[0].ReadOnlyPaths)
+	assert.NilError(t, Validate(y, false))
+
+	// The location-keyed merge uniquely appends the paths of all entries
+	y = LimaYAML{Mounts: []Mount{{
+		Location:      "/tmp/proj",
+		Writable:      ptr.Of(true),
+		ReadOnlyPaths: []string{"/tmp/proj/.git"},
+	}}}
+	o.Mounts = []Mount{{Location: "/tmp/proj", ReadOnlyPaths: []string{"/tmp/proj/secrets", "/tmp/proj/.git"}}}
+	FillDefault(&y, &d, &o, "lima.yaml")
+	assert.DeepEqual(t, []string{"/tmp/proj/.git", "/tmp/proj/secrets"}, y.Mounts[0].ReadOnlyPaths)
+
+	// Subpaths must live under the mount location
+	y.Mounts[0].ReadOnlyPaths = []string{"/etc/passwd"}
+	assert.ErrorContains(t, Validate(y, false), "mounts[0].readOnlyPaths[0]")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package limayaml
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// hostDiskSize returns the size in bytes of the filesystem containing path.
+func hostDiskSize(path string) (uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Blocks * uint64(st.Bsize), nil
+}
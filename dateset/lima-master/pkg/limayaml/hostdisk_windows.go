@@ -0,0 +1,20 @@
+//go:build windows
+
+package limayaml
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// hostDiskSize returns the size in bytes of the filesystem containing path.
+func hostDiskSize(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeBytesAvailable, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &freeBytesAvailable, &totalBytes, &totalFreeBytes); err != nil {
+		return 0, err
+	}
+	return totalBytes, nil
+}
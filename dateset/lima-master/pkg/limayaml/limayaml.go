@@ -7,31 +7,32 @@ import (
 )
 
 type LimaYAML struct {
-	VMType             *VMType         `yaml:"vmType,omitempty" json:"vmType,omitempty"`
-	OS                 *OS             `yaml:"os,omitempty" json:"os,omitempty"`
-	Arch               *Arch           `yaml:"arch,omitempty" json:"arch,omitempty"`
-	Images             []Image         `yaml:"images" json:"images"` // REQUIRED
-	CPUType            map[Arch]string `yaml:"cpuType,omitempty" json:"cpuType,omitempty"`
-	CPUs               *int            `yaml:"cpus,omitempty" json:"cpus,omitempty"`
-	Memory             *string         `yaml:"memory,omitempty" json:"memory,omitempty"` // go-units.RAMInBytes
-	Disk               *string         `yaml:"disk,omitempty" json:"disk,omitempty"`     // go-units.RAMInBytes
-	AdditionalDisks    []Disk          `yaml:"additionalDisks,omitempty" json:"additionalDisks,omitempty"`
-	Mounts             []Mount         `yaml:"mounts,omitempty" json:"mounts,omitempty"`
-	MountType          *MountType      `yaml:"mountType,omitempty" json:"mountType,omitempty"`
-	MountInotify       *bool           `yaml:"mountInotify,omitempty" json:"mountInotify,omitempty"`
-	SSH                SSH             `yaml:"ssh,omitempty" json:"ssh,omitempty"` // REQUIRED (FIXME)
-	Firmware           Firmware        `yaml:"firmware,omitempty" json:"firmware,omitempty"`
-	Audio              Audio           `yaml:"audio,omitempty" json:"audio,omitempty"`
-	Video              Video           `yaml:"video,omitempty" json:"video,omitempty"`
-	Provision          []Provision     `yaml:"provision,omitempty" json:"provision,omitempty"`
-	UpgradePackages    *bool           `yaml:"upgradePackages,omitempty" json:"upgradePackages,omitempty"`
-	Containerd         Containerd      `yaml:"containerd,omitempty" json:"containerd,omitempty"`
-	GuestInstallPrefix *string         `yaml:"guestInstallPrefix,omitempty" json:"guestInstallPrefix,omitempty"`
-	Probes             []Probe         `yaml:"probes,omitempty" json:"probes,omitempty"`
-	PortForwards       []PortForward   `yaml:"portForwards,omitempty" json:"portForwards,omitempty"`
-	CopyToHost         []CopyToHost    `yaml:"copyToHost,omitempty" json:"copyToHost,omitempty"`
-	Message            string          `yaml:"message,omitempty" json:"message,omitempty"`
-	Networks           []Network       `yaml:"networks,omitempty" json:"networks,omitempty"`
+	VMType                  *VMType                  `yaml:"vmType,omitempty" json:"vmType,omitempty"`
+	OS                      *OS                      `yaml:"os,omitempty" json:"os,omitempty"`
+	Arch                    *Arch                    `yaml:"arch,omitempty" json:"arch,omitempty"`
+	Images                  []Image                  `yaml:"images" json:"images"` // REQUIRED
+	CPUType                 map[Arch]string          `yaml:"cpuType,omitempty" json:"cpuType,omitempty"`
+	CPUs                    *int                     `yaml:"cpus,omitempty" json:"cpus,omitempty"`
+	Memory                  *string                  `yaml:"memory,omitempty" json:"memory,omitempty"` // go-units.RAMInBytes, or a percentage of the host memory (e.g. "50%")
+	Disk                    *string                  `yaml:"disk,omitempty" json:"disk,omitempty"`     // go-units.RAMInBytes, a percentage of the host disk, or "name:<diskName>" referencing an additionalDisks entry
+	AdditionalDisks         []Disk                   `yaml:"additionalDisks,omitempty" json:"additionalDisks,omitempty"`
+	Mounts                  []Mount                  `yaml:"mounts,omitempty" json:"mounts,omitempty"`
+	MountType               *MountType               `yaml:"mountType,omitempty" json:"mountType,omitempty"`
+	MountInotify            *bool                    `yaml:"mountInotify,omitempty" json:"mountInotify,omitempty"`
+	SSH                     SSH                      `yaml:"ssh,omitempty" json:"ssh,omitempty"` // REQUIRED (FIXME)
+	Firmware                Firmware                 `yaml:"firmware,omitempty" json:"firmware,omitempty"`
+	Audio                   Audio                    `yaml:"audio,omitempty" json:"audio,omitempty"`
+	Video                   Video                    `yaml:"video,omitempty" json:"video,omitempty"`
+	Provision               []Provision              `yaml:"provision,omitempty" json:"provision,omitempty"`
+	UpgradePackages         *bool                    `yaml:"upgradePackages,omitempty" json:"upgradePackages,omitempty"`
+	UpgradePackagesSchedule *UpgradePackagesSchedule `yaml:"upgradePackagesSchedule,omitempty" json:"upgradePackagesSchedule,omitempty"`
+	Containerd              Containerd               `yaml:"containerd,omitempty" json:"containerd,omitempty"`
+	GuestInstallPrefix      *string                  `yaml:"guestInstallPrefix,omitempty" json:"guestInstallPrefix,omitempty"`
+	Probes                  []Probe                  `yaml:"probes,omitempty" json:"probes,omitempty"`
+	PortForwards            []PortForward            `yaml:"portForwards,omitempty" json:"portForwards,omitempty"`
+	CopyToHost              []CopyToHost             `yaml:"copyToHost,omitempty" json:"copyToHost,omitempty"`
+	Message                 string                   `yaml:"message,omitempty" json:"message,omitempty"`
+	Networks                []Network                `yaml:"networks,omitempty" json:"networks,omitempty"`
 	// `network` was deprecated in Lima v0.7.0, removed in Lima v0.14.0. Use `networks` instead.
 	Env          map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
 	DNS          []net.IP          `yaml:"dns,omitempty" json:"dns,omitempty"`
@@ -42,6 +43,15 @@ type LimaYAML struct {
 	Rosetta           Rosetta        `yaml:"rosetta,omitempty" json:"rosetta,omitempty"`
 	Plain             *bool          `yaml:"plain,omitempty" json:"plain,omitempty"`
 	TimeZone          *string        `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	HostCgroup        HostCgroup     `yaml:"hostCgroup,omitempty" json:"hostCgroup,omitempty"`
+}
+
+// HostCgroup places the QEMU process in a host cgroup (Linux hosts only) with
+// CPU/memory limits derived from the instance's cpus/memory plus a headroom
+// factor, so one instance cannot starve the host.
+type HostCgroup struct {
+	Enabled  *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`   // default: false
+	Headroom *float64 `yaml:"headroom,omitempty" json:"headroom,omitempty"` // fraction on top of cpus/memory, default: 0.1
 }
 
 type (
@@ -49,6 +59,8 @@ type (
 	Arch      = string
 	MountType = string
 	VMType    = string
+
+	UpgradePackagesSchedule = string
 )
 
 const (
@@ -67,6 +79,10 @@ const (
 	QEMU VMType = "qemu"
 	VZ   VMType = "vz"
 	WSL2 VMType = "wsl2"
+
+	UpgradePackagesScheduleDaily   UpgradePackagesSchedule = "daily"
+	UpgradePackagesScheduleWeekly  UpgradePackagesSchedule = "weekly"
+	UpgradePackagesScheduleMonthly UpgradePackagesSchedule = "monthly"
 )
 
 type Rosetta struct {
@@ -104,12 +120,16 @@ type Disk struct {
 }
 
 type Mount struct {
-	Location   string   `yaml:"location" json:"location"` // REQUIRED
-	MountPoint string   `yaml:"mountPoint,omitempty" json:"mountPoint,omitempty"`
-	Writable   *bool    `yaml:"writable,omitempty" json:"writable,omitempty"`
-	SSHFS      SSHFS    `yaml:"sshfs,omitempty" json:"sshfs,omitempty"`
-	NineP      NineP    `yaml:"9p,omitempty" json:"9p,omitempty"`
-	Virtiofs   Virtiofs `yaml:"virtiofs,omitempty" json:"virtiofs,omitempty"`
+	Location   string `yaml:"location" json:"location"` // REQUIRED
+	MountPoint string `yaml:"mountPoint,omitempty" json:"mountPoint,omitempty"`
+	Writable   *bool  `yaml:"writable,omitempty" json:"writable,omitempty"`
+	// ReadOnlyPaths lists subpaths of a writable mount that are exposed
+	// read-only to the guest (e.g. to protect ".git"), for backends that
+	// support per-path RO binds.
+	ReadOnlyPaths []string `yaml:"readOnlyPaths,omitempty" json:"readOnlyPaths,omitempty"`
+	SSHFS         SSHFS    `yaml:"sshfs,omitempty" json:"sshfs,omitempty"`
+	NineP         NineP    `yaml:"9p,omitempty" json:"9p,omitempty"`
+	Virtiofs      Virtiofs `yaml:"virtiofs,omitempty" json:"virtiofs,omitempty"`
 }
 
 type SFTPDriver = string
@@ -144,6 +164,10 @@ type SSH struct {
 	ForwardAgent      *bool `yaml:"forwardAgent,omitempty" json:"forwardAgent,omitempty"`           // default: false
 	ForwardX11        *bool `yaml:"forwardX11,omitempty" json:"forwardX11,omitempty"`               // default: false
 	ForwardX11Trusted *bool `yaml:"forwardX11Trusted,omitempty" json:"forwardX11Trusted,omitempty"` // default: false
+
+	// IdentityFiles points at specific private keys to be used for the generated
+	// SSH config, in addition to the keys Lima generates and discovers.
+	IdentityFiles []string `yaml:"identityFiles,omitempty" json:"identityFiles,omitempty"`
 }
 
 type Firmware struct {
@@ -151,9 +175,18 @@ type Firmware struct {
 	// LegacyBIOS is ignored for aarch64.
 	LegacyBIOS *bool `yaml:"legacyBIOS,omitempty" json:"legacyBIOS,omitempty"`
 
+	// LegacyBIOSAuto inspects the image for a UEFI ESP and falls back to
+	// legacy BIOS when none is found; it takes precedence over LegacyBIOS.
+	LegacyBIOSAuto *bool `yaml:"legacyBIOSAuto,omitempty" json:"legacyBIOSAuto,omitempty"`
+
 	// Images specify UEFI images (edk2-aarch64-code.fd.gz).
 	// Defaults to built-in UEFI.
 	Images []FileWithVMType `yaml:"images,omitempty" json:"images,omitempty"`
+
+	// SecureBoot explicitly enables or disables UEFI Secure Boot.
+	// When nil, the firmware default is used.
+	// SecureBoot is incompatible with LegacyBIOS.
+	SecureBoot *bool `yaml:"secureBoot,omitempty" json:"secureBoot,omitempty"`
 }
 
 type Audio struct {
@@ -163,6 +196,8 @@ type Audio struct {
 
 type VNCOptions struct {
 	Display *string `yaml:"display,omitempty" json:"display,omitempty"`
+	// Password protects the VNC display; when unset a random password is generated
+	Password *string `yaml:"password,omitempty" json:"password,omitempty"`
 }
 
 type Video struct {
@@ -184,6 +219,10 @@ type Provision struct {
 	Mode                            ProvisionMode `yaml:"mode" json:"mode"` // default: "system"
 	SkipDefaultDependencyResolution *bool         `yaml:"skipDefaultDependencyResolution,omitempty" json:"skipDefaultDependencyResolution,omitempty"`
 	Script                          string        `yaml:"script" json:"script"`
+	// File refers to a file containing the script body, relative to the instance dir
+	// unless absolute; mutually exclusive with Script.
+	File    string  `yaml:"file,omitempty" json:"file,omitempty"`
+	Timeout *string `yaml:"timeout,omitempty" json:"timeout,omitempty"` // time.Duration; default: DefaultSystemProvisionTimeout for "system", unset (unbounded) otherwise
 }
 
 type Containerd struct {
@@ -247,18 +286,25 @@ type Network struct {
 	SwitchPortDeprecated uint16 `yaml:"switchPort,omitempty" json:"switchPort,omitempty"` // VDE Switch port, not TCP/UDP port (only used by VDE networking)
 	MACAddress           string `yaml:"macAddress,omitempty" json:"macAddress,omitempty"`
 	Interface            string `yaml:"interface,omitempty" json:"interface,omitempty"`
+	// DNS overrides the resolvers for this interface in the guest's per-link
+	// resolver configuration (e.g. a VPN interface with its own servers)
+	DNS []net.IP `yaml:"dns,omitempty" json:"dns,omitempty"`
 }
 
 type HostResolver struct {
 	Enabled *bool             `yaml:"enabled,omitempty" json:"enabled,omitempty"`
 	IPv6    *bool             `yaml:"ipv6,omitempty" json:"ipv6,omitempty"`
 	Hosts   map[string]string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	// Nameservers are the upstream servers that queries not matched by Hosts
+	// are forwarded to, instead of the servers from the host configuration.
+	Nameservers []net.IP `yaml:"nameservers,omitempty" json:"nameservers,omitempty"`
 }
 
 type CACertificates struct {
 	RemoveDefaults *bool    `yaml:"removeDefaults,omitempty" json:"removeDefaults,omitempty"` // default: false
 	Files          []string `yaml:"files,omitempty" json:"files,omitempty"`
 	Certs          []string `yaml:"certs,omitempty" json:"certs,omitempty"`
+	TrustStores    []string `yaml:"trustStores,omitempty" json:"trustStores,omitempty"` // well-known bundles by name, e.g. "system", "mozilla"
 }
 
 // DEPRECATED types below
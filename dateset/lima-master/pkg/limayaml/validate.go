@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/docker/go-units"
 	"github.com/lima-vm/lima/pkg/localpathutil"
@@ -99,6 +100,8 @@ func Validate(y LimaYAML, warn bool) error {
 		switch arch {
 		case AARCH64, X8664, ARMV7L, RISCV64:
 			// these are the only supported architectures
+		case "*":
+			// applies to all architectures unless overridden by a specific arch
 		default:
 			return fmt.Errorf("field `cpuType` uses unsupported arch %q", arch)
 		}
@@ -108,12 +111,34 @@ func Validate(y LimaYAML, warn bool) error {
 		return errors.New("field `cpus` must be set")
 	}
 
+	if strings.HasSuffix(*y.Memory, "%") {
+		// FillDefault resolves valid percentages into absolute sizes
+		return fmt.Errorf("field `memory` has an invalid percentage %q; it must be a number between 1 and 100", *y.Memory)
+	}
 	if _, err := units.RAMInBytes(*y.Memory); err != nil {
 		return fmt.Errorf("field `memory` has an invalid value: %w", err)
 	}
 
-	if _, err := units.RAMInBytes(*y.Disk); err != nil {
-		return fmt.Errorf("field `memory` has an invalid value: %w", err)
+	if diskName, ok := strings.CutPrefix(*y.Disk, "name:"); ok {
+		// "name:<diskName>" reuses an additional disk (e.g. a pre-created data
+		// disk shared between instances) instead of allocating a new one.
+		if diskName == "" {
+			return errors.New("field `disk` must name a disk when using the \"name:\" form")
+		}
+		found := false
+		for _, disk := range y.AdditionalDisks {
+			if disk.Name == diskName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("field `disk` refers to a disk %q that is not listed in `additionalDisks`", diskName)
+		}
+	} else if strings.HasSuffix(*y.Disk, "%") {
+		return fmt.Errorf("field `disk` has an invalid percentage %q; it must be a number between 1 and 100", *y.Disk)
+	} else if _, err := units.RAMInBytes(*y.Disk); err != nil {
+		return fmt.Errorf("field `disk` has an invalid value: %w", err)
 	}
 
 	u, err := osutil.LimaUser(false)
@@ -151,6 +176,16 @@ func Validate(y LimaYAML, warn bool) error {
 		if _, err := units.RAMInBytes(*f.NineP.Msize); err != nil {
 			return fmt.Errorf("field `msize` has an invalid value: %w", err)
 		}
+
+		for j, ro := range f.ReadOnlyPaths {
+			roLoc, err := localpathutil.Expand(ro)
+			if err != nil {
+				return fmt.Errorf("field `mounts[%d].readOnlyPaths[%d]` refers to an unexpandable path: %q: %w", i, j, ro, err)
+			}
+			if !strings.HasPrefix(roLoc, loc+"/") {
+				return fmt.Errorf("field `mounts[%d].readOnlyPaths[%d]` must be under the mount location %q, got %q", i, j, f.Location, ro)
+			}
+		}
 	}
 
 	if *y.SSH.LocalPort != 0 {
@@ -158,6 +193,17 @@ func Validate(y LimaYAML, warn bool) error {
 			return err
 		}
 	}
+	for i, f := range y.SSH.IdentityFiles {
+		expanded, err := localpathutil.Expand(f)
+		if err != nil {
+			return fmt.Errorf("field `ssh.identityFiles[%d]` refers to an invalid local file path: %q: %w", i, f, err)
+		}
+		if warn {
+			if _, err := os.Stat(expanded); err != nil {
+				logrus.WithError(err).Warnf("field `ssh.identityFiles[%d]` %q is not accessible", i, f)
+			}
+		}
+	}
 
 	switch *y.MountType {
 	case REVSSHFS, NINEP, VIRTIOFS, WSLMount:
@@ -175,6 +221,10 @@ func Validate(y LimaYAML, warn bool) error {
 
 	// y.Firmware.LegacyBIOS is ignored for aarch64, but not a fatal error.
 
+	if y.Firmware.SecureBoot != nil && *y.Firmware.SecureBoot && y.Firmware.LegacyBIOS != nil && *y.Firmware.LegacyBIOS {
+		logrus.Warn("field `firmware.secureBoot` is incompatible with `firmware.legacyBIOS` and will be ignored")
+	}
+
 	for i, p := range y.Provision {
 		switch p.Mode {
 		case ProvisionModeSystem, ProvisionModeUser, ProvisionModeBoot:
@@ -187,9 +237,29 @@ func Validate(y LimaYAML, warn bool) error {
 			return fmt.Errorf("field `provision[%d].mode` must one of %q, %q, %q, or %q",
 				i, ProvisionModeSystem, ProvisionModeUser, ProvisionModeBoot, ProvisionModeDependency)
 		}
+		if p.File != "" {
+			if p.Script != "" {
+				return fmt.Errorf("field `provision[%d].file` and field `provision[%d].script` are mutually exclusive", i, i)
+			}
+			// FillDefault consumes a readable file into Script and clears File
+			return fmt.Errorf("field `provision[%d].file` refers to a file that could not be read", i)
+		}
 		if strings.Contains(p.Script, "LIMA_CIDATA") {
 			logrus.Warn("provisioning scripts should not reference the LIMA_CIDATA variables")
 		}
+		if p.Timeout != nil {
+			if _, err := time.ParseDuration(*p.Timeout); err != nil {
+				return fmt.Errorf("field `provision[%d].timeout` has an invalid value: %w", i, err)
+			}
+		}
+	}
+	if y.UpgradePackagesSchedule != nil {
+		switch *y.UpgradePackagesSchedule {
+		case UpgradePackagesScheduleDaily, UpgradePackagesScheduleWeekly, UpgradePackagesScheduleMonthly:
+		default:
+			return fmt.Errorf("field `upgradePackagesSchedule` must be %q, %q, or %q; got %q",
+				UpgradePackagesScheduleDaily, UpgradePackagesScheduleWeekly, UpgradePackagesScheduleMonthly, *y.UpgradePackagesSchedule)
+		}
 	}
 	needsContainerdArchives := (y.Containerd.User != nil && *y.Containerd.User) || (y.Containerd.System != nil && *y.Containerd.System)
 	if needsContainerdArchives && len(y.Containerd.Archives) == 0 {
@@ -300,9 +370,40 @@ func Validate(y LimaYAML, warn bool) error {
 		return fmt.Errorf("field `dns` must be empty when field `HostResolver.Enabled` is true")
 	}
 
+	for i, ip := range y.HostResolver.Nameservers {
+		if ip == nil || ip.To16() == nil {
+			return fmt.Errorf("field `hostResolver.nameservers[%d]` is not a valid IP address", i)
+		}
+	}
+
+	if y.HostCgroup.Enabled != nil && *y.HostCgroup.Enabled {
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("field `hostCgroup.enabled` is only supported on Linux hosts, got %q", runtime.GOOS)
+		}
+		if y.VMType != nil && *y.VMType != QEMU {
+			return fmt.Errorf("field `hostCgroup.enabled` requires `vmType` %q; got %q", QEMU, *y.VMType)
+		}
+	}
+	if y.HostCgroup.Headroom != nil && *y.HostCgroup.Headroom < 0 {
+		return fmt.Errorf("field `hostCgroup.headroom` must not be negative; got %f", *y.HostCgroup.Headroom)
+	}
+
+	for i, store := range y.CACertificates.TrustStores {
+		if _, ok := caTrustStorePaths[store]; !ok {
+			return fmt.Errorf("field `caCerts.trustStores[%d]` must reference a known trust store; got %q", i, store)
+		}
+	}
+
 	if err := validateNetwork(y, warn); err != nil {
 		return err
 	}
+	if warn && y.VMType != nil && *y.VMType == QEMU && y.Video.VNC.Display != nil && *y.Video.VNC.Display != "" {
+		vnchost, _, _ := strings.Cut(*y.Video.VNC.Display, ":")
+		if vnchost != "" && vnchost != "127.0.0.1" && vnchost != "localhost" &&
+			(y.Video.VNC.Password == nil || *y.Video.VNC.Password == "") {
+			logrus.Warnf("field `video.vnc.display` binds to non-loopback address %q; set `video.vnc.password` to require authentication", vnchost)
+		}
+	}
 	if warn {
 		warnExperimental(y)
 	}
@@ -313,6 +414,26 @@ func validateNetwork(y LimaYAML, warn bool) error {
 	interfaceName := make(map[string]int)
 	for i, nw := range y.Networks {
 		field := fmt.Sprintf("networks[%d]", i)
+		// `Lima`, `Socket`, and `VNL` are mutually exclusive and exactly one is
+		// required (unless `vzNAT` is used); check this up front so a
+		// misconfigured interface fails with one clear error instead of
+		// whichever pairwise check happens to trip first.
+		set := 0
+		for _, v := range []string{nw.Lima, nw.Socket, nw.VNLDeprecated} {
+			if v != "" {
+				set++
+			}
+		}
+		iface := nw.Interface
+		if iface == "" {
+			iface = field
+		}
+		if set > 1 {
+			return fmt.Errorf("interface %q: fields `%s.lima`, `%s.socket`, and `%s.vnl` are mutually exclusive; only one of them can be set", iface, field, field, field)
+		}
+		if set == 0 && (nw.VZNAT == nil || !*nw.VZNAT) {
+			return fmt.Errorf("interface %q: one of the fields `%s.lima`, `%s.socket`, or `%s.vnl` must be set", iface, field, field, field)
+		}
 		if nw.Lima != "" {
 			config, err := networks.Config()
 			if err != nil {
@@ -423,6 +544,11 @@ func validateNetwork(y LimaYAML, warn bool) error {
 				return fmt.Errorf("field `%s.macAddress` must be a 48 bit (6 bytes) MAC address; actual length of %q is %d bytes", field, nw.MACAddress, len(hw))
 			}
 		}
+		for j, ip := range nw.DNS {
+			if ip == nil || ip.To16() == nil {
+				return fmt.Errorf("field `%s.dns[%d]` is not a valid IP address", field, j)
+			}
+		}
 		// FillDefault() will make sure that nw.Interface is not the empty string
 		if len(nw.Interface) >= 16 {
 			return fmt.Errorf("field `%s.interface` must be less than 16 bytes, but is %d bytes: %q", field, len(nw.Interface), nw.Interface)
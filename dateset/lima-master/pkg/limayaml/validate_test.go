@@ -0,0 +1,35 @@
+package limayaml
+
+import (
+	"testing"
+
+	"github.com/lima-vm/lima/pkg/ptr"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateNetworkExclusivity(t *testing.T) {
+	// Exactly one of lima/socket/vnl is required
+	y := LimaYAML{Networks: []Network{{}}}
+	err := validateNetwork(y, false)
+	assert.ErrorContains(t, err, "`networks[0].lima`, `networks[0].socket`, or `networks[0].vnl` must be set")
+
+	y = LimaYAML{Networks: []Network{{Socket: "/nonexistent/socket", VNLDeprecated: "vde:///nonexistent/switch"}}}
+	err = validateNetwork(y, false)
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	// The named interface shows up in the error
+	y = LimaYAML{Networks: []Network{{Interface: "lima0"}}}
+	err = validateNetwork(y, false)
+	assert.ErrorContains(t, err, `interface "lima0"`)
+
+	// A single socket is fine; a nonexistent path is only checked at start
+	y = LimaYAML{Networks: []Network{{Socket: "/nonexistent/socket"}}}
+	err = validateNetwork(y, false)
+	assert.NilError(t, err)
+
+	// vzNAT alone is fine as well (when the vmType matches)
+	vmType := VZ
+	y = LimaYAML{VMType: &vmType, Networks: []Network{{VZNAT: ptr.Of(true)}}}
+	err = validateNetwork(y, false)
+	assert.NilError(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/docker/go-units"
 	"github.com/lima-vm/lima/pkg/driver"
@@ -38,6 +39,10 @@ func EnsureDisk(ctx context.Context, driver *driver.BaseDriver) error {
 			return fileutils.Errors(errs)
 		}
 	}
+	if strings.HasPrefix(*driver.Yaml.Disk, "name:") {
+		// the root disk reuses a named additional disk; there is no data volume to size here
+		return nil
+	}
 	diskSize, _ := units.RAMInBytes(*driver.Yaml.Disk)
 	if diskSize == 0 {
 		return nil
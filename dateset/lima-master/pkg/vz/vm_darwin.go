@@ -204,12 +204,24 @@ func createVM(driver *driver.BaseDriver) (*vz.VirtualMachine, error) {
 }
 
 func createInitialConfig(driver *driver.BaseDriver) (*vz.VirtualMachineConfiguration, error) {
+	// The VZ driver can only boot with EFI, so fail early when the firmware
+	// settings (including `firmware.legacyBIOSAuto` image inspection) resolve
+	// to legacy BIOS.
+	baseDisk := filepath.Join(driver.Instance.Dir, filenames.BaseDisk)
+	if limayaml.ResolveLegacyBIOS(driver.Yaml, baseDisk) {
+		return nil, errors.New("the firmware configuration resolved to legacy BIOS, which is not supported for VZ driver")
+	}
+
 	efiVariableStore, err := getEFI(driver)
 	if err != nil {
 		return nil, err
 	}
 
-	bootLoader, err := vz.NewEFIBootLoader(vz.WithEFIVariableStore(efiVariableStore))
+	bootLoaderOpts := []vz.NewEFIBootLoaderOption{vz.WithEFIVariableStore(efiVariableStore)}
+	if secureBoot := driver.Yaml.Firmware.SecureBoot; secureBoot != nil {
+		bootLoaderOpts = append(bootLoaderOpts, vz.WithSecureBoot(*secureBoot))
+	}
+	bootLoader, err := vz.NewEFIBootLoader(bootLoaderOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -25,11 +26,13 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/cilium/ebpf"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/merbridge/merbridge/config"
@@ -43,11 +46,51 @@ var (
 	// todo optimize do not use global var
 	globalPm process.ProcessManager
 	podMap   = map[string]map[string]*v1.Pod{}
-	// key is ns name, value means ambient mode
-	nsMap = map[string]bool{}
+	// key is ns name, value holds the namespace labels we care about
+	nsMap = map[string]nsConfig{}
 	lock  sync.RWMutex
 )
 
+type nsConfig struct {
+	ambient  bool // istio.io/dataplane-mode: ambient
+	disabled bool // merbridge.io/redirection: disabled
+}
+
+// localIPMapUpdate and localIPMapDelete wrap the local_pod_ips eBPF map
+// accesses, routing IPv6 addresses to the parallel local_pod_ips_v6 map;
+// they are variables so tests can hook them.
+var localIPMapUpdate = func(podIP string, p *podConfig, p6 *podConfigV6) error {
+	_ip, err := linux.IP2Linux(podIP)
+	if err != nil {
+		return fmt.Errorf("parse ip %s error: %v", podIP, err)
+	}
+	if ip := net.ParseIP(podIP); ip != nil && ip.To4() == nil {
+		m := ebpfs.GetLocalIPV6Map()
+		if m == nil {
+			// the running BPF programs predate IPv6 support
+			log.Debugf("local_pod_ips_v6 map is unavailable, skipping %s", podIP)
+			return nil
+		}
+		return m.Update(_ip, p6, ebpf.UpdateAny)
+	}
+	return ebpfs.GetLocalIPMap().Update(_ip, p, ebpf.UpdateAny)
+}
+
+var localIPMapDelete = func(podIP string) error {
+	_ip, err := linux.IP2Linux(podIP)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(podIP); ip != nil && ip.To4() == nil {
+		m := ebpfs.GetLocalIPV6Map()
+		if m == nil {
+			return nil
+		}
+		return m.Delete(_ip)
+	}
+	return ebpfs.GetLocalIPMap().Delete(_ip)
+}
+
 func RunLocalPodController(client kubernetes.Interface, pm process.ProcessManager, stop chan struct{}) error {
 	globalPm = pm
 	var err error
@@ -66,6 +109,12 @@ func RunLocalPodController(client kubernetes.Interface, pm process.ProcessManage
 	if err = ebpfs.AttachMBProgs(); err != nil {
 		return fmt.Errorf("failed to attach ebpf programs: %v", err)
 	}
+	// the maps may have just been re-created (e.g. after an agent restart),
+	// so replay every pod we already know about into them
+	ResyncAllPods()
+	// the watcher can miss delete events (e.g. while reconnecting), which
+	// would leave stale IPs in local_pod_ips forever; reconcile periodically
+	go runPeriodicReconcile(client, stop)
 	if config.EnableCNI {
 		<-stop
 	} else {
@@ -82,6 +131,84 @@ func RunLocalPodController(client kubernetes.Interface, pm process.ProcessManage
 	return nil
 }
 
+// ReconcileInterval determines how often the periodic reconcile loop compares
+// podMap against the pods currently running on this node. Setting it to zero
+// (before calling RunLocalPodController) disables the loop.
+var ReconcileInterval = 60 * time.Second
+
+func runPeriodicReconcile(client kubernetes.Interface, stop chan struct{}) {
+	if ReconcileInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reconcileLocalPods(client)
+		}
+	}
+}
+
+// reconcileLocalPods lists the pods running on this node and drops every pod
+// we still track but the API server no longer knows about, removing its IPs
+// from the eBPF map like a regular delete event would.
+func reconcileLocalPods(client kubernetes.Interface) {
+	localName, err := os.Hostname()
+	if err != nil {
+		log.Errorf("reconcile: get hostname error: %v", err)
+		return
+	}
+	list, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + localName,
+	})
+	if err != nil {
+		log.Errorf("reconcile: list pods error: %v", err)
+		return
+	}
+	live := make(map[string]struct{}, len(list.Items))
+	for i := range list.Items {
+		live[list.Items[i].Namespace+"/"+list.Items[i].Name] = struct{}{}
+	}
+	lock.Lock()
+	var stale []*v1.Pod
+	for ns, nsPods := range podMap {
+		for name, pod := range nsPods {
+			if _, ok := live[ns+"/"+name]; !ok {
+				stale = append(stale, pod)
+				delete(nsPods, name)
+			}
+		}
+	}
+	lock.Unlock()
+	// deleteFunc does not take the lock, so run it outside of it
+	for _, pod := range stale {
+		log.Infof("reconcile: removing stale pod %s/%s from local_pod_ips", pod.Namespace, pod.Name)
+		deleteFunc(pod)
+	}
+}
+
+// ResyncAllPods re-pushes every pod we have seen into the eBPF map. It is
+// meant to run after the pinned maps are (re)loaded, as in-flight pods would
+// otherwise lose their config until their next update.
+func ResyncAllPods() {
+	lock.RLock()
+	resync := make([]*v1.Pod, 0, len(podMap))
+	for _, nsPods := range podMap {
+		for _, pod := range nsPods {
+			resync = append(resync, pod)
+		}
+	}
+	lock.RUnlock()
+	log.Infof("resyncing %d pods into the local_pod_ips map", len(resync))
+	// addFunc takes the lock itself, so run it outside the read lock
+	for _, pod := range resync {
+		addFunc(pod)
+	}
+}
+
 func createLocalPodController(client kubernetes.Interface) pods.Watcher {
 	localName, err := os.Hostname()
 	if err != nil {
@@ -104,6 +231,14 @@ type cidr struct {
 	_    [3]uint8 // pad
 }
 
+// cidrV6 is the entry layout of the parallel local_pod_ips_v6 map; the IPv4
+// map keeps its original 8-byte entries so existing BPF programs keep working
+type cidrV6 struct {
+	net  [4]uint32 // network order
+	mask uint8
+	_    [3]uint8 // pad
+}
+
 type podConfig struct {
 	statusPort       uint16
 	_                uint16 // pad
@@ -115,14 +250,26 @@ type podConfig struct {
 	excludeOutPorts  [MaxItemLen]uint16
 }
 
+// podConfigV6 mirrors podConfig for the local_pod_ips_v6 map, which carries
+// 128-bit ranges; the port arrays are identical for both address families
+type podConfigV6 struct {
+	statusPort       uint16
+	_                uint16 // pad
+	excludeOutRanges [MaxItemLen]cidrV6
+	includeOutRanges [MaxItemLen]cidrV6
+	includeInPorts   [MaxItemLen]uint16
+	includeOutPorts  [MaxItemLen]uint16
+	excludeInPorts   [MaxItemLen]uint16
+	excludeOutPorts  [MaxItemLen]uint16
+}
+
 func addFunc(obj interface{}) {
 	if ns, ok := obj.(*v1.Namespace); ok {
 		lock.Lock()
-		isAmbient := false
-		if ns.Labels["istio.io/dataplane-mode"] == "ambient" {
-			isAmbient = true
+		nsMap[ns.Name] = nsConfig{
+			ambient:  ns.Labels["istio.io/dataplane-mode"] == "ambient",
+			disabled: ns.Labels["merbridge.io/redirection"] == "disabled",
 		}
-		nsMap[ns.Name] = isAmbient
 		if _, ok := podMap[ns.Name]; !ok {
 			podMap[ns.Name] = make(map[string]*v1.Pod)
 		}
@@ -153,15 +300,25 @@ func addFunc(obj interface{}) {
 	case config.ModeOsm:
 		isInjectedSidecar = pods.IsOsmInjectedSidecar(pod)
 	}
+	nsCfg := nsMap[pod.Namespace]
 	// see https://github.com/istio/istio/blob/3b3ca8ec1632961e355f398f7357ebed9b13aa43/cni/pkg/ambient/podutil.go#L44
-	isAmbient := nsMap[pod.Namespace] && !isInjectedSidecar && pod.Labels["ambient.istio.io/redirection"] != "disabled"
+	isAmbient := nsCfg.ambient && !isInjectedSidecar && pod.Labels["ambient.istio.io/redirection"] != "disabled"
 	isZtunnel := pod.Labels["app"] == "ztunnel"
 	isInMesh := false
 	if isAmbient || isInjectedSidecar || isZtunnel {
 		isInMesh = true
 	}
+	if nsCfg.disabled {
+		// redirection is force-disabled for the whole namespace
+		isInMesh = false
+	}
 
 	if !isInMesh {
+		// the pod may have been in the mesh before (e.g. its namespace was
+		// just force-disabled), so make sure no stale entries remain
+		for _, podIP := range getPodIPs(pod) {
+			_ = localIPMapDelete(podIP)
+		}
 		return
 	}
 	if isZtunnel {
@@ -169,19 +326,30 @@ func addFunc(obj interface{}) {
 	}
 	log.Debugf("got pod updated %s/%s, isAmbient: %v, isZtunnel: %v", pod.Namespace, pod.Name, isAmbient, isZtunnel)
 
-	_ip, _ := linux.IP2Linux(pod.Status.PodIP)
-	log.Infof("update local_pod_ips with ip: %s", pod.Status.PodIP)
 	p := podConfig{}
+	p6 := podConfigV6{}
+	podID := pod.Namespace + "/" + pod.Name
 	if config.Mode == config.ModeKuma {
-		parsePodConfigFromAnnotationsKuma(pod.Annotations, &p)
+		parsePodConfigFromAnnotationsKuma(pod.Annotations, &p, podID)
+	} else if config.Mode == config.ModeLinkerd {
+		parsePodConfigFromAnnotationsLinkerd(pod.Annotations, &p, podID)
 	} else if config.Mode == config.ModeOsm {
-		parsePodConfigFromAnnotationsOsm(pod.Annotations, &p)
+		parsePodConfigFromAnnotationsOsm(pod.Annotations, &p, &p6, podID)
 	} else {
-		parsePodConfigFromAnnotations(pod.Annotations, &p)
-	}
-	err := ebpfs.GetLocalIPMap().Update(_ip, &p, ebpf.UpdateAny)
-	if err != nil {
-		log.Errorf("update local_pod_ips %s error: %v", pod.Status.PodIP, err)
+		parsePodConfigFromAnnotations(pod.Annotations, &p, &p6, podID)
+	}
+	// the status port and the port filters are address-family independent
+	p6.statusPort = p.statusPort
+	p6.includeInPorts = p.includeInPorts
+	p6.includeOutPorts = p.includeOutPorts
+	p6.excludeInPorts = p.excludeInPorts
+	p6.excludeOutPorts = p.excludeOutPorts
+	// Update the map for every pod IP so dual-stack pods are covered as well
+	for _, podIP := range getPodIPs(pod) {
+		log.Infof("update local_pod_ips with ip: %s", podIP)
+		if err := localIPMapUpdate(podIP, &p, &p6); err != nil {
+			log.Errorf("update local_pod_ips %s error: %v", podIP, err)
+		}
 	}
 	if globalPm != nil {
 		if err := globalPm.OnPodStatusChanged(pod.Status.PodIP, isInMesh, isAmbient, isZtunnel); err != nil {
@@ -190,6 +358,20 @@ func addFunc(obj interface{}) {
 	}
 }
 
+// getPodIPs returns all IPs assigned to a pod, starting with Status.PodIP
+func getPodIPs(pod *v1.Pod) []string {
+	ips := make([]string, 0, len(pod.Status.PodIPs)+1)
+	if len(pod.Status.PodIP) != 0 {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	for _, ip := range pod.Status.PodIPs {
+		if ip.IP != pod.Status.PodIP && len(ip.IP) != 0 {
+			ips = append(ips, ip.IP)
+		}
+	}
+	return ips
+}
+
 func getPortsFromString(v string) []uint16 {
 	var ports []uint16
 	for _, vv := range strings.Split(v, ",") {
@@ -203,14 +385,18 @@ func getPortsFromString(v string) []uint16 {
 	return ports
 }
 
-func getIPRangesFromString(v string) []cidr {
-	var ranges []cidr
+func getIPRangesFromString(v string) ([]cidr, []cidrV6) {
+	var v4Ranges []cidr
+	var v6Ranges []cidrV6
 	for _, vv := range strings.Split(v, ",") {
 		if vv == "*" {
-			ranges = append(ranges, cidr{
+			v4Ranges = append(v4Ranges, cidr{
 				net:  0,
 				mask: 0,
 			})
+			v6Ranges = append(v6Ranges, cidrV6{
+				mask: 0,
+			})
 			continue
 		}
 		if p := strings.TrimSpace(vv); p != "" {
@@ -219,21 +405,61 @@ func getIPRangesFromString(v string) []cidr {
 				log.Errorf("parse cidr from %s error: %v", vv, err)
 				continue
 			}
-			c := cidr{}
 			ones, _ := n.Mask.Size()
-			c.mask = uint8(ones)
-			if len(n.IP) == 16 {
-				c.net = *(*uint32)(unsafe.Pointer(&n.IP[12]))
+			if ip4 := n.IP.To4(); ip4 != nil {
+				c := cidr{mask: uint8(ones)}
+				c.net = *(*uint32)(unsafe.Pointer(&ip4[0]))
+				v4Ranges = append(v4Ranges, c)
 			} else {
-				c.net = *(*uint32)(unsafe.Pointer(&n.IP[0]))
+				c := cidrV6{mask: uint8(ones)}
+				copy((*[16]byte)(unsafe.Pointer(&c.net[0]))[:], n.IP.To16())
+				v6Ranges = append(v6Ranges, c)
 			}
-			ranges = append(ranges, c)
 		}
 	}
-	return ranges
+	return v4Ranges, v6Ranges
+}
+
+// warnDroppedItems reports entries silently dropped when an annotation list
+// exceeds the eBPF array cap; it is a variable so tests can hook it.
+var warnDroppedItems = func(podID, field string, dropped int) {
+	log.Warnf("pod %s: %s has %d entries over the cap of %d, dropping them", podID, field, dropped, MaxItemLen)
 }
 
-func parsePodConfigFromAnnotations(annotations map[string]string, pod *podConfig) {
+// fillPorts copies up to MaxItemLen ports into dst and warns about the rest
+func fillPorts(dst *[MaxItemLen]uint16, src []uint16, podID, field string) {
+	for i, p := range src {
+		if i >= MaxItemLen {
+			warnDroppedItems(podID, field, len(src)-MaxItemLen)
+			break
+		}
+		dst[i] = p
+	}
+}
+
+// fillRanges copies up to MaxItemLen IP ranges into dst and warns about the rest
+func fillRanges(dst *[MaxItemLen]cidr, src []cidr, podID, field string) {
+	for i, p := range src {
+		if i >= MaxItemLen {
+			warnDroppedItems(podID, field, len(src)-MaxItemLen)
+			break
+		}
+		dst[i] = p
+	}
+}
+
+// fillRangesV6 is fillRanges for the entries of the IPv6 map
+func fillRangesV6(dst *[MaxItemLen]cidrV6, src []cidrV6, podID, field string) {
+	for i, p := range src {
+		if i >= MaxItemLen {
+			warnDroppedItems(podID, field, len(src)-MaxItemLen)
+			break
+		}
+		dst[i] = p
+	}
+}
+
+func parsePodConfigFromAnnotations(annotations map[string]string, pod *podConfig, pod6 *podConfigV6, podID string) {
 	statusPort := 15021
 	if v, ok := annotations["status.sidecar.istio.io/port"]; ok {
 		vv, err := strconv.ParseUint(v, 10, 16)
@@ -246,101 +472,66 @@ func parsePodConfigFromAnnotations(annotations map[string]string, pod *podConfig
 	if v, ok := annotations["traffic.sidecar.istio.io/excludeInboundPorts"]; ok {
 		excludeInboundPorts = append(excludeInboundPorts, getPortsFromString(v)...)
 	}
-	if len(excludeInboundPorts) > 0 {
-		for i, p := range excludeInboundPorts {
-			if i >= MaxItemLen {
-				break
-			}
-			pod.excludeInPorts[i] = p
-		}
-	}
+	fillPorts(&pod.excludeInPorts, excludeInboundPorts, podID, "excludeInboundPorts")
 	if v, ok := annotations["traffic.sidecar.istio.io/excludeOutboundPorts"]; ok {
 		excludeOutboundPorts := getPortsFromString(v)
-		if len(excludeOutboundPorts) > 0 {
-			for i, p := range excludeOutboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.excludeOutPorts[i] = p
-			}
-		}
+		fillPorts(&pod.excludeOutPorts, excludeOutboundPorts, podID, "excludeOutboundPorts")
 	}
 
+	// "*" means no include filter at all, which the eBPF programs express as
+	// an empty include array, so the array is simply left zeroed
 	if v, ok := annotations["traffic.sidecar.istio.io/includeInboundPorts"]; ok {
-		includeInboundPorts := getPortsFromString(v)
-		if len(includeInboundPorts) > 0 {
-			for i, p := range includeInboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeInPorts[i] = p
-			}
+		if strings.TrimSpace(v) != "*" {
+			includeInboundPorts := getPortsFromString(v)
+			fillPorts(&pod.includeInPorts, includeInboundPorts, podID, "includeInboundPorts")
 		}
 	}
 	if v, ok := annotations["traffic.sidecar.istio.io/includeOutboundPorts"]; ok {
-		includeOutboundPorts := getPortsFromString(v)
-		if len(includeOutboundPorts) > 0 {
-			for i, p := range includeOutboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeOutPorts[i] = p
-			}
+		if strings.TrimSpace(v) != "*" {
+			includeOutboundPorts := getPortsFromString(v)
+			fillPorts(&pod.includeOutPorts, includeOutboundPorts, podID, "includeOutboundPorts")
 		}
 	}
 
 	if v, ok := annotations["traffic.sidecar.istio.io/excludeOutboundIPRanges"]; ok {
-		excludeOutboundIPRanges := getIPRangesFromString(v)
-		if len(excludeOutboundIPRanges) > 0 {
-			for i, p := range excludeOutboundIPRanges {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.excludeOutRanges[i] = p
-			}
-		}
+		excludeV4, excludeV6 := getIPRangesFromString(v)
+		fillRanges(&pod.excludeOutRanges, excludeV4, podID, "excludeOutboundIPRanges")
+		fillRangesV6(&pod6.excludeOutRanges, excludeV6, podID, "excludeOutboundIPRanges")
 	}
 	if v, ok := annotations["traffic.sidecar.istio.io/includeOutboundIPRanges"]; ok {
-		includeOutboundIPRanges := getIPRangesFromString(v)
-		if len(includeOutboundIPRanges) > 0 {
-			for i, p := range includeOutboundIPRanges {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeOutRanges[i] = p
-			}
-		}
+		includeV4, includeV6 := getIPRangesFromString(v)
+		fillRanges(&pod.includeOutRanges, includeV4, podID, "includeOutboundIPRanges")
+		fillRangesV6(&pod6.includeOutRanges, includeV6, podID, "includeOutboundIPRanges")
 	}
 }
 
-func parsePodConfigFromAnnotationsKuma(annotations map[string]string, pod *podConfig) {
+func parsePodConfigFromAnnotationsKuma(annotations map[string]string, pod *podConfig, podID string) {
 	excludeInboundPorts := []uint16{9901, 15001, 15006, 15010} // todo changeme
 	// FIXME: Whether to need to consistent with the naming Isito an Annotation: hump method
 	if v, ok := annotations["traffic.kuma.io/exclude-inbound-ports"]; ok {
 		excludeInboundPorts = append(excludeInboundPorts, getPortsFromString(v)...)
 	}
-	if len(excludeInboundPorts) > 0 {
-		for i, p := range excludeInboundPorts {
-			if i >= MaxItemLen {
-				break
-			}
-			pod.excludeInPorts[i] = p
-		}
-	}
+	fillPorts(&pod.excludeInPorts, excludeInboundPorts, podID, "excludeInboundPorts")
 	if v, ok := annotations["traffic.kuma.io/exclude-outbound-ports"]; ok {
 		excludeOutboundPorts := getPortsFromString(v)
-		if len(excludeOutboundPorts) > 0 {
-			for i, p := range excludeOutboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.excludeOutPorts[i] = p
-			}
-		}
+		fillPorts(&pod.excludeOutPorts, excludeOutboundPorts, podID, "excludeOutboundPorts")
+	}
+}
+
+func parsePodConfigFromAnnotationsLinkerd(annotations map[string]string, pod *podConfig, podID string) {
+	// the linkerd-proxy inbound, admin and metrics ports
+	excludeInboundPorts := []uint16{4143, 4190, 4191} // todo changeme
+	if v, ok := annotations["config.linkerd.io/skip-inbound-ports"]; ok {
+		excludeInboundPorts = append(excludeInboundPorts, getPortsFromString(v)...)
+	}
+	fillPorts(&pod.excludeInPorts, excludeInboundPorts, podID, "skip-inbound-ports")
+	if v, ok := annotations["config.linkerd.io/skip-outbound-ports"]; ok {
+		excludeOutboundPorts := getPortsFromString(v)
+		fillPorts(&pod.excludeOutPorts, excludeOutboundPorts, podID, "skip-outbound-ports")
 	}
 }
 
-func parsePodConfigFromAnnotationsOsm(annotations map[string]string, pod *podConfig) {
+func parsePodConfigFromAnnotationsOsm(annotations map[string]string, pod *podConfig, pod6 *podConfigV6, podID string) {
 	statusPort := 15021
 	if v, ok := annotations["openservicemesh.io/port"]; ok {
 		vv, err := strconv.ParseUint(v, 10, 16)
@@ -353,70 +544,30 @@ func parsePodConfigFromAnnotationsOsm(annotations map[string]string, pod *podCon
 	if v, ok := annotations["openservicemesh.io/inbound-port-exclusion-list"]; ok {
 		excludeInboundPorts = append(excludeInboundPorts, getPortsFromString(v)...)
 	}
-	if len(excludeInboundPorts) > 0 {
-		for i, p := range excludeInboundPorts {
-			if i >= MaxItemLen {
-				break
-			}
-			pod.excludeInPorts[i] = p
-		}
-	}
+	fillPorts(&pod.excludeInPorts, excludeInboundPorts, podID, "excludeInboundPorts")
 	if v, ok := annotations["openservicemesh.io/outbound-port-exclusion-list"]; ok {
 		excludeOutboundPorts := getPortsFromString(v)
-		if len(excludeOutboundPorts) > 0 {
-			for i, p := range excludeOutboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.excludeOutPorts[i] = p
-			}
-		}
+		fillPorts(&pod.excludeOutPorts, excludeOutboundPorts, podID, "excludeOutboundPorts")
 	}
 
 	if v, ok := annotations["openservicemesh.io/inbound-port-inclusion-list"]; ok {
 		includeInboundPorts := getPortsFromString(v)
-		if len(includeInboundPorts) > 0 {
-			for i, p := range includeInboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeInPorts[i] = p
-			}
-		}
+		fillPorts(&pod.includeInPorts, includeInboundPorts, podID, "includeInboundPorts")
 	}
 	if v, ok := annotations["openservicemesh.io/outbound-port-inclusion-list"]; ok {
 		includeOutboundPorts := getPortsFromString(v)
-		if len(includeOutboundPorts) > 0 {
-			for i, p := range includeOutboundPorts {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeOutPorts[i] = p
-			}
-		}
+		fillPorts(&pod.includeOutPorts, includeOutboundPorts, podID, "includeOutboundPorts")
 	}
 
 	if v, ok := annotations["openservicemesh.io/outbound-ip-range-exclusion-list"]; ok {
-		excludeOutboundIPRanges := getIPRangesFromString(v)
-		if len(excludeOutboundIPRanges) > 0 {
-			for i, p := range excludeOutboundIPRanges {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.excludeOutRanges[i] = p
-			}
-		}
+		excludeV4, excludeV6 := getIPRangesFromString(v)
+		fillRanges(&pod.excludeOutRanges, excludeV4, podID, "excludeOutboundIPRanges")
+		fillRangesV6(&pod6.excludeOutRanges, excludeV6, podID, "excludeOutboundIPRanges")
 	}
 	if v, ok := annotations["openservicemesh.io/outbound-ip-range-inclusion-list"]; ok {
-		includeOutboundIPRanges := getIPRangesFromString(v)
-		if len(includeOutboundIPRanges) > 0 {
-			for i, p := range includeOutboundIPRanges {
-				if i >= MaxItemLen {
-					break
-				}
-				pod.includeOutRanges[i] = p
-			}
-		}
+		includeV4, includeV6 := getIPRangesFromString(v)
+		fillRanges(&pod.includeOutRanges, includeV4, podID, "includeOutboundIPRanges")
+		fillRangesV6(&pod6.includeOutRanges, includeV6, podID, "includeOutboundIPRanges")
 	}
 }
 
@@ -442,8 +593,9 @@ func updateFunc(old, cur interface{}) {
 func deleteFunc(obj interface{}) {
 	if pod, ok := obj.(*v1.Pod); ok {
 		log.Debugf("got pod delete %s/%s", pod.Namespace, pod.Name)
-		_ip, _ := linux.IP2Linux(pod.Status.PodIP)
-		_ = ebpfs.GetLocalIPMap().Delete(_ip)
+		for _, podIP := range getPodIPs(pod) {
+			_ = localIPMapDelete(podIP)
+		}
 		if globalPm != nil {
 			if err := globalPm.OnPodDeleted(pod.Status.PodIP); err != nil {
 				log.Debugf("OnPodDeleted error: %v", err)
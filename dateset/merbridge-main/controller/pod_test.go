@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_parseConfig(t *testing.T) {
@@ -52,6 +54,36 @@ func Test_parseConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "includeInboundPorts",
+			annotations: map[string]string{
+				"traffic.sidecar.istio.io/includeInboundPorts": "80,443",
+			},
+			expect: &podConfig{
+				statusPort: 15021,
+				excludeInPorts: [MaxItemLen]uint16{
+					15006, 15001, 15008, 15090, 15021, 15020, 15000,
+				},
+				includeInPorts: [MaxItemLen]uint16{
+					80,
+					443,
+				},
+			},
+		},
+		{
+			name: "includeInboundPorts wildcard",
+			annotations: map[string]string{
+				"traffic.sidecar.istio.io/includeInboundPorts":  "*",
+				"traffic.sidecar.istio.io/includeOutboundPorts": "*",
+			},
+			// an all-zero include array means "no include filter"
+			expect: &podConfig{
+				statusPort: 15021,
+				excludeInPorts: [MaxItemLen]uint16{
+					15006, 15001, 15008, 15090, 15021, 15020, 15000,
+				},
+			},
+		},
 		{
 			name: "excludeOutboundIPRanges",
 			annotations: map[string]string{
@@ -114,7 +146,7 @@ func Test_parseConfig(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			out := podConfig{}
-			parsePodConfigFromAnnotations(c.annotations, &out)
+			parsePodConfigFromAnnotations(c.annotations, &out, &podConfigV6{}, "default/test-pod")
 			assert.Equal(t, c.expect, &out)
 		})
 	}
@@ -166,8 +198,194 @@ func Test_parsePodConfigFromAnnotationsKuma(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			out := podConfig{}
-			parsePodConfigFromAnnotationsKuma(c.annotations, &out)
+			parsePodConfigFromAnnotationsKuma(c.annotations, &out, "default/test-pod")
 			assert.Equal(t, c.expect, &out)
 		})
 	}
 }
+
+func Test_parsePodConfigFromAnnotationsLinkerd(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expect      *podConfig
+	}{
+		{
+			name:        "empty",
+			annotations: map[string]string{},
+			expect: &podConfig{
+				excludeInPorts: [MaxItemLen]uint16{
+					4143, 4190, 4191,
+				},
+			},
+		},
+		{
+			name: "skip-inbound-ports",
+			annotations: map[string]string{
+				"config.linkerd.io/skip-inbound-ports": "12345,80",
+			},
+			expect: &podConfig{
+				excludeInPorts: [MaxItemLen]uint16{
+					4143, 4190, 4191,
+					12345,
+					80,
+				},
+			},
+		},
+		{
+			name: "skip-outbound-ports",
+			annotations: map[string]string{
+				"config.linkerd.io/skip-outbound-ports": "12345,80",
+			},
+			expect: &podConfig{
+				excludeInPorts: [MaxItemLen]uint16{
+					4143, 4190, 4191,
+				},
+				excludeOutPorts: [MaxItemLen]uint16{
+					12345, 80,
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := podConfig{}
+			parsePodConfigFromAnnotationsLinkerd(c.annotations, &out, "default/test-pod")
+			assert.Equal(t, c.expect, &out)
+		})
+	}
+}
+
+func Test_getIPRangesFromString(t *testing.T) {
+	cases := []struct {
+		name     string
+		ranges   string
+		expect   []cidr
+		expectV6 []cidrV6
+	}{
+		{
+			name:   "ipv4",
+			ranges: "192.168.0.0/16",
+			expect: []cidr{
+				{
+					net:  0x0000a8c0,
+					mask: 16,
+				},
+			},
+		},
+		{
+			name:   "ipv6",
+			ranges: "fd00::/8",
+			expectV6: []cidrV6{
+				{
+					net:  [4]uint32{0x000000fd},
+					mask: 8,
+				},
+			},
+		},
+		{
+			name:   "dual stack",
+			ranges: "10.0.0.0/8,2001:db8::/32",
+			expect: []cidr{
+				{
+					net:  0x0000000a,
+					mask: 8,
+				},
+			},
+			expectV6: []cidrV6{
+				{
+					net:  [4]uint32{0xb8d80120},
+					mask: 32,
+				},
+			},
+		},
+		{
+			name:     "wildcard",
+			ranges:   "*",
+			expect:   []cidr{{}},
+			expectV6: []cidrV6{{}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v4, v6 := getIPRangesFromString(c.ranges)
+			assert.Equal(t, c.expect, v4)
+			assert.Equal(t, c.expectV6, v6)
+		})
+	}
+}
+
+func Test_addFuncNamespaceRedirectionDisabled(t *testing.T) {
+	var updated, deleted []string
+	savedUpdate, savedDelete := localIPMapUpdate, localIPMapDelete
+	localIPMapUpdate = func(podIP string, p *podConfig, p6 *podConfigV6) error {
+		updated = append(updated, podIP)
+		return nil
+	}
+	localIPMapDelete = func(podIP string) error {
+		deleted = append(deleted, podIP)
+		return nil
+	}
+	defer func() {
+		localIPMapUpdate, localIPMapDelete = savedUpdate, savedDelete
+		lock.Lock()
+		delete(podMap, "test-ns")
+		delete(nsMap, "test-ns")
+		lock.Unlock()
+	}()
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "test-ns",
+		Labels: map[string]string{"istio.io/dataplane-mode": "ambient"},
+	}}
+	addFunc(ns)
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "app"},
+		Status:     v1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	addFunc(pod)
+	assert.Equal(t, []string{"10.0.0.1"}, updated)
+	assert.Empty(t, deleted)
+
+	// force-disabling redirection for the namespace removes the entries of all its pods
+	ns.Labels["merbridge.io/redirection"] = "disabled"
+	addFunc(ns)
+	assert.Equal(t, []string{"10.0.0.1"}, deleted)
+
+	// pods added while the namespace is disabled never make it into the map
+	updated = nil
+	addFunc(pod)
+	assert.Empty(t, updated)
+}
+
+func Test_parseConfigWarnsOnDroppedItems(t *testing.T) {
+	type droppedItem struct {
+		podID   string
+		field   string
+		dropped int
+	}
+	var got []droppedItem
+	savedWarn := warnDroppedItems
+	warnDroppedItems = func(podID, field string, dropped int) {
+		got = append(got, droppedItem{podID, field, dropped})
+	}
+	defer func() { warnDroppedItems = savedWarn }()
+
+	// 7 default excluded ports plus 15 from the annotation exceed MaxItemLen (20) by 2
+	annotations := map[string]string{
+		"traffic.sidecar.istio.io/excludeInboundPorts": "1,2,3,4,5,6,7,8,9,10,11,12,13,14,15",
+	}
+	out := podConfig{}
+	parsePodConfigFromAnnotations(annotations, &out, &podConfigV6{}, "default/test-pod")
+
+	assert.Equal(t, []droppedItem{{"default/test-pod", "excludeInboundPorts", 2}}, got)
+
+	// Lists within the cap must not warn
+	got = nil
+	out = podConfig{}
+	parsePodConfigFromAnnotations(map[string]string{
+		"traffic.sidecar.istio.io/excludeInboundPorts": "12345,80",
+	}, &out, &podConfigV6{}, "default/test-pod")
+	assert.Empty(t, got)
+}
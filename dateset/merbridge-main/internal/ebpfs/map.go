@@ -26,6 +26,7 @@ import (
 
 var (
 	localPodIpsMap       *ebpf.Map
+	localPodIpsV6Map     *ebpf.Map
 	pairOriginIpsMap     *ebpf.Map
 	cgroupInfoMap        *ebpf.Map
 	settingsMap          *ebpf.Map
@@ -38,6 +39,9 @@ func InitLoadPinnedMap() error {
 	if err != nil {
 		return fmt.Errorf("load map error: %v", err)
 	}
+	// the v6 map only exists with recent BPF programs; older dataplanes keep
+	// working without IPv6 pod support
+	localPodIpsV6Map, _ = ebpf.LoadPinnedMap(config.LocalPodIpsV6, &ebpf.LoadPinOptions{})
 	pairOriginIpsMap, err = ebpf.LoadPinnedMap(config.PairOriginalDst, &ebpf.LoadPinOptions{})
 	if err != nil {
 		return fmt.Errorf("load map error: %v", err)
@@ -64,6 +68,15 @@ func GetLocalIPMap() *ebpf.Map {
 	return localPodIpsMap
 }
 
+// GetLocalIPV6Map returns nil when the running BPF programs do not pin a
+// local_pod_ips_v6 map
+func GetLocalIPV6Map() *ebpf.Map {
+	if localPodIpsMap == nil {
+		_ = InitLoadPinnedMap()
+	}
+	return localPodIpsV6Map
+}
+
 func GetPairOriginalMap() *ebpf.Map {
 	if pairOriginIpsMap == nil {
 		_ = InitLoadPinnedMap()
@@ -0,0 +1,132 @@
+// Copyright 2024 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// unwrapToken returns the underlying value of v, along with the *token that
+// carries its position when the configuration was parsed in pedantic mode.
+func unwrapToken(v any) (any, *token) {
+	if tk, ok := v.(*token); ok {
+		return tk.Value(), tk
+	}
+	return v, nil
+}
+
+// at formats the position of a token for error messages; it is empty when no
+// position information is available.
+func at(tk *token) string {
+	if tk == nil {
+		return ""
+	}
+	return fmt.Sprintf(" on line %d, position %d", tk.Line(), tk.Position())
+}
+
+// Schema lists the keys allowed in a configuration block. A non-nil nested
+// Schema restricts the keys of the block (or array of blocks) stored under
+// that key; a nil value allows arbitrary content below the key.
+type Schema map[string]Schema
+
+// Validate checks a parsed configuration against a schema of known keys and
+// returns one error per unknown key, so that misspellings like `jetsream`
+// instead of `jetstream` are caught instead of being silently ignored. When
+// the configuration was parsed in pedantic mode (e.g. via
+// ParseFileWithChecks), errors carry the line and position of the offending
+// value. Validation is opt-in: Parse itself stays permissive and keeps
+// unknown keys in the mapping.
+func Validate(m map[string]any, schema Schema) []error {
+	return validateBlock(m, schema, "")
+}
+
+func validateBlock(m map[string]any, schema Schema, path string) []error {
+	var errs []error
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v, tk := unwrapToken(m[key])
+		sub, known := schema[key]
+		if !known {
+			errs = append(errs, fmt.Errorf("unknown key '%s%s'%s", path, key, at(tk)))
+			continue
+		}
+		if sub == nil {
+			continue
+		}
+		switch vv := v.(type) {
+		case map[string]any:
+			errs = append(errs, validateBlock(vv, sub, path+key+".")...)
+		case []any:
+			for _, e := range vv {
+				ev, _ := unwrapToken(e)
+				if em, ok := ev.(map[string]any); ok {
+					errs = append(errs, validateBlock(em, sub, path+key+".")...)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// CheckAuthorization validates the `authorization` block of a parsed
+// configuration: `user` and `users` are mutually exclusive, `timeout` must be
+// numeric, and `users` must be an array of maps. When the configuration was
+// parsed in pedantic mode (e.g. via ParseFileWithChecks), errors carry the
+// line and position of the offending value. A configuration without an
+// authorization block passes the check.
+func CheckAuthorization(m map[string]any) error {
+	v, ok := m["authorization"]
+	if !ok {
+		return nil
+	}
+	v, tk := unwrapToken(v)
+	auth, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("authorization should be a map%s", at(tk))
+	}
+
+	_, hasUser := auth["user"]
+	if usersRaw, hasUsers := auth["users"]; hasUsers {
+		usersVal, usersTk := unwrapToken(usersRaw)
+		if hasUser {
+			return fmt.Errorf("authorization can not use a single 'user' and a 'users' array together%s", at(usersTk))
+		}
+		users, ok := usersVal.([]any)
+		if !ok {
+			return fmt.Errorf("authorization 'users' should be an array%s", at(usersTk))
+		}
+		for i, u := range users {
+			uv, utk := unwrapToken(u)
+			if _, ok := uv.(map[string]any); !ok {
+				return fmt.Errorf("authorization 'users' entry %d should be a map%s", i, at(utk))
+			}
+		}
+	}
+
+	if timeoutRaw, hasTimeout := auth["timeout"]; hasTimeout {
+		timeoutVal, timeoutTk := unwrapToken(timeoutRaw)
+		switch timeoutVal.(type) {
+		case int64, float64:
+		default:
+			return fmt.Errorf("authorization 'timeout' should be a number, got '%v'%s", timeoutVal, at(timeoutTk))
+		}
+	}
+
+	return nil
+}
@@ -801,6 +801,13 @@ func (lx *lexer) isVariable() bool {
 	return false
 }
 
+// Check if the unquoted string scanned so far, including the current `]`, is
+// an array-expanding variable reference of the form `$NAME[]`.
+func (lx *lexer) isArrayVariable() bool {
+	str := lx.input[lx.start:lx.pos]
+	return len(str) > 3 && str[0] == '$' && strings.HasSuffix(str, "[]")
+}
+
 // lexQuotedString consumes the inner contents of a string. It assumes that the
 // beginning '"' has already been consumed and ignored. It will not interpret any
 // internal contents.
@@ -855,6 +862,12 @@ func lexString(lx *lexer) stateFn {
 	case r == '\\':
 		lx.addCurrentStringPart(1)
 		return lexStringEscape
+	case r == arrayEnd && lx.isArrayVariable():
+		// Keep the `[]` marker with the variable reference so the parser can
+		// expand the value into a list.
+		lx.start += 1
+		lx.emit(itemVariable)
+		return lx.pop()
 	// Termination of non-quoted strings
 	case isNL(r) || r == eof || r == optValTerm ||
 		r == arrayValTerm || r == arrayEnd || r == mapEnd ||
@@ -27,8 +27,10 @@ package conf
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -56,13 +58,47 @@ type parser struct {
 
 	// pedantic reports error when configuration is not correct.
 	pedantic bool
+
+	// strict reports an error when a key is defined more than once within
+	// the same map block, instead of silently keeping the last value.
+	strict bool
+
+	// First occurrence of each key per open map context, strict mode only.
+	seenKeys []map[string]item
 }
 
 // Parse will return a map of keys to any, although concrete types
 // underly them. The values supported are string, bool, int64, float64, DateTime.
 // Arrays and nested Maps are also supported.
 func Parse(data string) (map[string]any, error) {
-	p, err := parse(data, "", false)
+	p, err := parse(data, "", false, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.mapping, nil
+}
+
+// ParseStrict is equivalent to Parse, but errors when a key is defined more
+// than once within the same block, reporting the position of both
+// definitions. Note that strict and pedantic modes are mutually exclusive.
+func ParseStrict(data string) (map[string]any, error) {
+	p, err := parse(data, "", false, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.mapping, nil
+}
+
+// ParseReader parses the configuration read from r, producing the same
+// results as Parse on the same bytes. The name is used for error line and
+// position reporting, like the file path in ParseFile, and may be empty.
+func ParseReader(r io.Reader, name string) (map[string]any, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+
+	p, err := parse(string(data), name, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +112,7 @@ func ParseFile(fp string) (map[string]any, error) {
 		return nil, fmt.Errorf("error opening config file: %v", err)
 	}
 
-	p, err := parse(string(data), fp, false)
+	p, err := parse(string(data), fp, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +126,7 @@ func ParseFileWithChecks(fp string) (map[string]any, error) {
 		return nil, err
 	}
 
-	p, err := parse(string(data), fp, true)
+	p, err := parse(string(data), fp, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +134,12 @@ func ParseFileWithChecks(fp string) (map[string]any, error) {
 	return p.mapping, nil
 }
 
+// MaxNestingDepth is the maximum depth that maps and arrays may nest inside
+// a configuration. Parsing aborts with an error once it is exceeded, which
+// guards against pathological inputs like an endless `{{{{...` sequence.
+// It may be raised by embedders that need deeper structures.
+var MaxNestingDepth = 512
+
 type token struct {
 	item         item
 	value        any
@@ -125,7 +167,7 @@ func (t *token) Position() int {
 	return t.item.pos
 }
 
-func parse(data, fp string, pedantic bool) (p *parser, err error) {
+func parse(data, fp string, pedantic, strict bool) (p *parser, err error) {
 	p = &parser{
 		mapping:  make(map[string]any),
 		lx:       lex(data),
@@ -134,6 +176,7 @@ func parse(data, fp string, pedantic bool) (p *parser, err error) {
 		ikeys:    make([]item, 0, 4),
 		fp:       filepath.Dir(fp),
 		pedantic: pedantic,
+		strict:   strict,
 	}
 	p.pushContext(p.mapping)
 
@@ -163,6 +206,11 @@ func (p *parser) next() item {
 func (p *parser) pushContext(ctx any) {
 	p.ctxs = append(p.ctxs, ctx)
 	p.ctx = ctx
+	if p.strict {
+		if _, ok := ctx.(map[string]any); ok {
+			p.seenKeys = append(p.seenKeys, make(map[string]item))
+		}
+	}
 }
 
 func (p *parser) popContext() any {
@@ -173,6 +221,11 @@ func (p *parser) popContext() any {
 	last := p.ctxs[li]
 	p.ctxs = p.ctxs[0:li]
 	p.ctx = p.ctxs[len(p.ctxs)-1]
+	if p.strict {
+		if _, ok := last.(map[string]any); ok {
+			p.seenKeys = p.seenKeys[:len(p.seenKeys)-1]
+		}
+	}
 	return last
 }
 
@@ -205,12 +258,11 @@ func (p *parser) popItemKey() item {
 }
 
 func (p *parser) processItem(it item, fp string) error {
-	setValue := func(it item, v any) {
+	setValue := func(it item, v any) error {
 		if p.pedantic {
-			p.setValue(&token{it, v, false, fp})
-		} else {
-			p.setValue(v)
+			return p.setValue(&token{it, v, false, fp})
 		}
+		return p.setValue(v)
 	}
 
 	switch it.typ {
@@ -222,65 +274,30 @@ func (p *parser) processItem(it item, fp string) error {
 		// includes without many breaking changes.
 		p.pushKey(it.val)
 
-		if p.pedantic {
+		if p.pedantic || p.strict {
 			p.pushItemKey(it)
 		}
 	case itemMapStart:
+		if err := p.checkDepth(it); err != nil {
+			return err
+		}
 		newCtx := make(map[string]any)
 		p.pushContext(newCtx)
 	case itemMapEnd:
-		setValue(it, p.popContext())
+		return setValue(it, p.popContext())
 	case itemString:
 		// FIXME(dlc) sanitize string?
-		setValue(it, it.val)
+		return setValue(it, it.val)
 	case itemInteger:
-		lastDigit := 0
-		for _, r := range it.val {
-			if !unicode.IsDigit(r) && r != '-' {
-				break
-			}
-			lastDigit++
-		}
-		numStr := it.val[:lastDigit]
-		num, err := strconv.ParseInt(numStr, 10, 64)
+		num, ok, err := parseNumberWithSuffix(it.val)
 		if err != nil {
-			if e, ok := err.(*strconv.NumError); ok &&
-				e.Err == strconv.ErrRange {
-				return fmt.Errorf("integer '%s' is out of the range", it.val)
-			}
-			return fmt.Errorf("expected integer, but got '%s'", it.val)
-		}
-		// Process a suffix
-		suffix := strings.ToLower(strings.TrimSpace(it.val[lastDigit:]))
-
-		switch suffix {
-		case "":
-			setValue(it, num)
-		case "k":
-			setValue(it, num*1000)
-		case "kb", "ki", "kib":
-			setValue(it, num*1024)
-		case "m":
-			setValue(it, num*1000*1000)
-		case "mb", "mi", "mib":
-			setValue(it, num*1024*1024)
-		case "g":
-			setValue(it, num*1000*1000*1000)
-		case "gb", "gi", "gib":
-			setValue(it, num*1024*1024*1024)
-		case "t":
-			setValue(it, num*1000*1000*1000*1000)
-		case "tb", "ti", "tib":
-			setValue(it, num*1024*1024*1024*1024)
-		case "p":
-			setValue(it, num*1000*1000*1000*1000*1000)
-		case "pb", "pi", "pib":
-			setValue(it, num*1024*1024*1024*1024*1024)
-		case "e":
-			setValue(it, num*1000*1000*1000*1000*1000*1000)
-		case "eb", "ei", "eib":
-			setValue(it, num*1024*1024*1024*1024*1024*1024)
+			return fmt.Errorf("%s on line %d, position %d", err, it.line, it.pos)
 		}
+		if !ok {
+			// Unknown suffixes were historically ignored
+			break
+		}
+		return setValue(it, num)
 	case itemFloat:
 		num, err := strconv.ParseFloat(it.val, 64)
 		if err != nil {
@@ -290,13 +307,13 @@ func (p *parser) processItem(it item, fp string) error {
 			}
 			return fmt.Errorf("expected float, but got '%s'", it.val)
 		}
-		setValue(it, num)
+		return setValue(it, num)
 	case itemBool:
 		switch strings.ToLower(it.val) {
 		case "true", "yes", "on":
-			setValue(it, true)
+			return setValue(it, true)
 		case "false", "no", "off":
-			setValue(it, false)
+			return setValue(it, false)
 		default:
 			return fmt.Errorf("expected boolean value, but got '%s'", it.val)
 		}
@@ -307,16 +324,22 @@ func (p *parser) processItem(it item, fp string) error {
 			return fmt.Errorf(
 				"expected Zulu formatted DateTime, but got '%s'", it.val)
 		}
-		setValue(it, dt)
+		return setValue(it, dt)
 	case itemArrayStart:
+		if err := p.checkDepth(it); err != nil {
+			return err
+		}
 		var array = make([]any, 0)
 		p.pushContext(array)
 	case itemArrayEnd:
 		array := p.ctx
 		p.popContext()
-		setValue(it, array)
+		return setValue(it, array)
 	case itemVariable:
-		value, found, err := p.lookupVariable(it.val)
+		// A `$NAME[]` reference requests the comma-separated value to be
+		// expanded into a list; a plain `$NAME` stays scalar.
+		varReference, asList := strings.CutSuffix(it.val, "[]")
+		value, found, err := p.lookupVariable(varReference, asList)
 		if err != nil {
 			return fmt.Errorf("variable reference for '%s' on line %d could not be parsed: %s",
 				it.val, it.line, err)
@@ -332,40 +355,88 @@ func (p *parser) processItem(it item, fp string) error {
 				// Mark the looked up variable as used, and make
 				// the variable reference become handled as a token.
 				tk.usedVariable = true
-				p.setValue(&token{it, tk.Value(), false, fp})
+				return p.setValue(&token{it, tk.Value(), false, fp})
 			default:
 				// Special case to add position context to bcrypt references.
-				p.setValue(&token{it, value, false, fp})
+				return p.setValue(&token{it, value, false, fp})
 			}
-		} else {
-			p.setValue(value)
 		}
+		return p.setValue(value)
 	case itemInclude:
-		var (
-			m   map[string]any
-			err error
-		)
-		if p.pedantic {
-			m, err = ParseFileWithChecks(filepath.Join(p.fp, it.val))
-		} else {
-			m, err = ParseFile(filepath.Join(p.fp, it.val))
+		// `include` also accepts a glob pattern to pull in several files at
+		// once, e.g. `include 'conf.d/*.conf'`; the matching files are merged
+		// in lexical order. A pattern matching no files is an error, unless
+		// the include is marked optional by prefixing it with '?'.
+		pattern, optional := strings.CutPrefix(it.val, "?")
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := filepath.Glob(filepath.Join(p.fp, pattern))
+			if err != nil {
+				return fmt.Errorf("error expanding include pattern '%s', %v", pattern, err)
+			}
+			if len(matches) == 0 && !optional {
+				return fmt.Errorf("include pattern '%s' matched no files", pattern)
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				if err := p.includeFile(it, match, match); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
-		if err != nil {
-			return fmt.Errorf("error parsing include file '%s', %v", it.val, err)
+		if optional {
+			if _, err := os.Stat(filepath.Join(p.fp, pattern)); os.IsNotExist(err) {
+				return nil
+			}
 		}
-		for k, v := range m {
-			p.pushKey(k)
+		return p.includeFile(it, filepath.Join(p.fp, pattern), pattern)
+	}
 
-			if p.pedantic {
-				switch tk := v.(type) {
-				case *token:
-					p.pushItemKey(tk.item)
-				}
+	return nil
+}
+
+// includeFile parses the config file at path and merges its keys into the
+// current context; name is how the file was referred to in the include
+// directive and is used for error reporting.
+func (p *parser) includeFile(it item, path, name string) error {
+	var (
+		m   map[string]any
+		err error
+	)
+	if p.pedantic {
+		m, err = ParseFileWithChecks(path)
+	} else {
+		m, err = ParseFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing include file '%s', %v", name, err)
+	}
+	for k, v := range m {
+		p.pushKey(k)
+
+		if p.pedantic {
+			switch tk := v.(type) {
+			case *token:
+				p.pushItemKey(tk.item)
 			}
-			p.setValue(v)
+		}
+		if p.strict {
+			// Included keys inherit the position of the include directive.
+			p.pushItemKey(item{itemKey, k, it.line, it.pos})
+		}
+		if err := p.setValue(v); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
+// checkDepth makes sure the context stack stays within MaxNestingDepth before
+// a new map or array context is pushed.
+func (p *parser) checkDepth(it item) error {
+	if len(p.ctxs) > MaxNestingDepth {
+		return fmt.Errorf("exceeded maximum nesting depth of %d on line %d", MaxNestingDepth, it.line)
+	}
 	return nil
 }
 
@@ -380,7 +451,7 @@ const bcryptPrefix = "2a$"
 // ignore array contexts and only process the map contexts..
 //
 // Returns true for ok if it finds something, similar to map.
-func (p *parser) lookupVariable(varReference string) (any, bool, error) {
+func (p *parser) lookupVariable(varReference string, asList bool) (any, bool, error) {
 	// Do special check to see if it is a raw bcrypt string.
 	if strings.HasPrefix(varReference, bcryptPrefix) {
 		return "$" + varReference, true, nil
@@ -392,6 +463,10 @@ func (p *parser) lookupVariable(varReference string) (any, bool, error) {
 		// Process if it is a map context
 		if m, ok := ctx.(map[string]any); ok {
 			if v, ok := m[varReference]; ok {
+				if s, isStr := v.(string); isStr && asList {
+					list, err := expandCommaList(s)
+					return list, ok, err
+				}
 				return v, ok, nil
 			}
 		}
@@ -400,6 +475,10 @@ func (p *parser) lookupVariable(varReference string) (any, bool, error) {
 	// If we are here, we have exhausted our context maps and still not found anything.
 	// Parse from the environment.
 	if vStr, ok := os.LookupEnv(varReference); ok {
+		if asList {
+			list, err := expandCommaList(vStr)
+			return list, ok, err
+		}
 		// Everything we get here will be a string value, so we need to process as a parser would.
 		if vmap, err := Parse(fmt.Sprintf("%s=%s", pkey, vStr)); err == nil {
 			v, ok := vmap[pkey]
@@ -411,7 +490,26 @@ func (p *parser) lookupVariable(varReference string) (any, bool, error) {
 	return nil, false, nil
 }
 
-func (p *parser) setValue(val any) {
+// expandCommaList parses each comma-separated element of vStr as the parser
+// would a scalar value, and returns the results as a list.
+func expandCommaList(vStr string) ([]any, error) {
+	elements := strings.Split(vStr, ",")
+	values := make([]any, 0, len(elements))
+	for _, element := range elements {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+		vmap, err := Parse(fmt.Sprintf("%s=%s", pkey, element))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, vmap[pkey])
+	}
+	return values, nil
+}
+
+func (p *parser) setValue(val any) error {
 	// Test to see if we are on an array or a map
 
 	// Array processing
@@ -424,6 +522,42 @@ func (p *parser) setValue(val any) {
 	if ctx, ok := p.ctx.(map[string]any); ok {
 		key := p.popKey()
 
+		// Keys may carry an explicit type annotation, e.g. `port(int): "4222"`,
+		// forcing the value to coerce to the annotated type.
+		if name, typ, annotated := splitKeyAnnotation(key); annotated {
+			key = name
+			switch v := val.(type) {
+			case *token:
+				cv, err := coerceValue(typ, v.value)
+				if err != nil {
+					if isSecretKey(key) {
+						return fmt.Errorf("error coercing value '%s' for key '%s' on line %d", redactedValue, key, v.Line())
+					}
+					return fmt.Errorf("error coercing value for key '%s' on line %d: %v", key, v.Line(), err)
+				}
+				v.value = cv
+			default:
+				cv, err := coerceValue(typ, val)
+				if err != nil {
+					if isSecretKey(key) {
+						return fmt.Errorf("error coercing value '%s' for key '%s'", redactedValue, key)
+					}
+					return fmt.Errorf("error coercing value for key '%s': %v", key, err)
+				}
+				val = cv
+			}
+		}
+
+		if p.strict {
+			ik := p.popItemKey()
+			seen := p.seenKeys[len(p.seenKeys)-1]
+			if first, ok := seen[key]; ok {
+				return fmt.Errorf("duplicate key '%s' on line %d, position %d; already defined on line %d, position %d",
+					key, ik.line, ik.pos, first.line, first.pos)
+			}
+			seen[key] = ik
+		}
+
 		if p.pedantic {
 			// Change the position to the beginning of the key
 			// since more useful when reporting errors.
@@ -439,4 +573,245 @@ func (p *parser) setValue(val any) {
 			ctx[key] = val
 		}
 	}
+	return nil
+}
+
+// parseNumberWithSuffix parses an integer with an optional size suffix, e.g. 1K or 16GB.
+// The second return value reports whether the suffix was recognized; unknown suffixes
+// are not an error for backwards compatibility.
+func parseNumberWithSuffix(val string) (int64, bool, error) {
+	lastDigit := 0
+	for _, r := range val {
+		if !unicode.IsDigit(r) && r != '-' {
+			break
+		}
+		lastDigit++
+	}
+	numStr := val[:lastDigit]
+	num, err := strconv.ParseInt(numStr, 10, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok &&
+			e.Err == strconv.ErrRange {
+			return 0, false, fmt.Errorf("integer '%s' is out of the range", val)
+		}
+		return 0, false, fmt.Errorf("expected integer, but got '%s'", val)
+	}
+	// Process a suffix
+	suffix := strings.ToLower(strings.TrimSpace(val[lastDigit:]))
+
+	var mult int64
+	switch suffix {
+	case "":
+		return num, true, nil
+	case "k":
+		mult = 1000
+	case "kb", "ki", "kib":
+		mult = 1024
+	case "m":
+		mult = 1000 * 1000
+	case "mb", "mi", "mib":
+		mult = 1024 * 1024
+	case "g":
+		mult = 1000 * 1000 * 1000
+	case "gb", "gi", "gib":
+		mult = 1024 * 1024 * 1024
+	case "t":
+		mult = 1000 * 1000 * 1000 * 1000
+	case "tb", "ti", "tib":
+		mult = 1024 * 1024 * 1024 * 1024
+	case "p":
+		mult = 1000 * 1000 * 1000 * 1000 * 1000
+	case "pb", "pi", "pib":
+		mult = 1024 * 1024 * 1024 * 1024 * 1024
+	case "e":
+		mult = 1000 * 1000 * 1000 * 1000 * 1000 * 1000
+	case "eb", "ei", "eib":
+		mult = 1024 * 1024 * 1024 * 1024 * 1024 * 1024
+	default:
+		return 0, false, nil
+	}
+	// Applying the suffix must not wrap around; silently turning e.g. 9999PiB
+	// into a negative limit would be hard to notice
+	res := num * mult
+	if res/mult != num {
+		return 0, false, fmt.Errorf("integer '%s' is out of the range", val)
+	}
+	return res, true, nil
+}
+
+// splitKeyAnnotation splits a key of the form `name(type)` into its name and type
+// annotation. Keys without an annotation are returned unchanged.
+func splitKeyAnnotation(key string) (name, typ string, ok bool) {
+	if !strings.HasSuffix(key, ")") {
+		return key, "", false
+	}
+	idx := strings.IndexByte(key, '(')
+	if idx <= 0 {
+		return key, "", false
+	}
+	name = strings.TrimSpace(key[:idx])
+	typ = strings.ToLower(strings.TrimSpace(key[idx+1 : len(key)-1]))
+	if name == "" || typ == "" {
+		return key, "", false
+	}
+	return name, typ, true
+}
+
+// coerceValue forces a value to the type given in a key annotation. Values already
+// having the annotated type pass through unchanged.
+func coerceValue(typ string, val any) (any, error) {
+	switch typ {
+	case "int":
+		switch v := val.(type) {
+		case int64:
+			return v, nil
+		case string:
+			if num, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return num, nil
+			}
+		}
+	case "float":
+		switch v := val.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if num, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return num, nil
+			}
+		}
+	case "bool":
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "on":
+				return true, nil
+			case "false", "no", "off":
+				return false, nil
+			}
+		}
+	case "duration":
+		switch v := val.(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			if d, err := time.ParseDuration(strings.TrimSpace(v)); err == nil {
+				return d, nil
+			}
+		}
+	case "bytes":
+		switch v := val.(type) {
+		case int64:
+			return v, nil
+		case string:
+			if num, ok, err := parseNumberWithSuffix(strings.TrimSpace(v)); err == nil && ok {
+				return num, nil
+			}
+		}
+	case "string":
+		if v, ok := val.(string); ok {
+			return v, nil
+		}
+	case "secret":
+		return &Secret{val}, nil
+	default:
+		return nil, fmt.Errorf("unknown type annotation '(%s)'", typ)
+	}
+	return nil, fmt.Errorf("value '%v' can not be coerced to %s", val, typ)
+}
+
+// redactedValue replaces secret values whenever they would be printed.
+const redactedValue = "***"
+
+// secretKeyNames is a heuristic list of key names whose values are considered
+// sensitive and are redacted in parse errors and by Marshal.
+var secretKeyNames = []string{"password", "pass", "token", "secret", "auth_token"}
+
+// isSecretKey reports whether values under this key should be redacted.
+func isSecretKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, name := range secretKeyNames {
+		if k == name || strings.HasSuffix(k, "_"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Secret wraps a sensitive value, e.g. one parsed from a `key(secret)` annotated
+// key. It prints as `***` but keeps the real value accessible via Value().
+type Secret struct {
+	value any
+}
+
+// Value returns the wrapped value.
+func (s *Secret) Value() any {
+	return s.value
+}
+
+func (s *Secret) String() string {
+	return redactedValue
+}
+
+func (s *Secret) GoString() string {
+	return redactedValue
+}
+
+// Marshal writes a parsed configuration back out in the conf format. Secret
+// values, either wrapped via a `(secret)` annotation or stored under a key
+// name matching the secret heuristics, are replaced with `***` so the output
+// is safe to log.
+func Marshal(m map[string]any) string {
+	var sb strings.Builder
+	marshalMap(&sb, m, 0)
+	return sb.String()
+}
+
+func marshalMap(sb *strings.Builder, m map[string]any, indent int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sb.WriteString(strings.Repeat("  ", indent))
+		sb.WriteString(k)
+		sb.WriteString(": ")
+		if isSecretKey(k) {
+			sb.WriteString(redactedValue)
+		} else {
+			marshalValue(sb, m[k], indent)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func marshalValue(sb *strings.Builder, val any, indent int) {
+	switch v := val.(type) {
+	case *token:
+		marshalValue(sb, v.Value(), indent)
+	case *Secret:
+		sb.WriteString(redactedValue)
+	case map[string]any:
+		sb.WriteString("{\n")
+		marshalMap(sb, v, indent+1)
+		sb.WriteString(strings.Repeat("  ", indent))
+		sb.WriteString("}")
+	case []any:
+		sb.WriteString("[")
+		for i, item := range v {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			marshalValue(sb, item, indent)
+		}
+		sb.WriteString("]")
+	case string:
+		sb.WriteString(strconv.Quote(v))
+	default:
+		fmt.Fprintf(sb, "%v", v)
+	}
 }
@@ -92,6 +92,39 @@ func TestMissingVariable(t *testing.T) {
 	}
 }
 
+var dupKeySample = `
+port: 4222
+monitor_port: 8222
+port: 4223
+`
+
+func TestParseStrictDuplicateKeys(t *testing.T) {
+	// The lenient default keeps the last value for JSON compatibility.
+	m, err := Parse(dupKeySample)
+	if err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+	if port := m["port"]; port != int64(4223) {
+		t.Fatalf("Expected the last value to win, got %v", port)
+	}
+
+	_, err = ParseStrict(dupKeySample)
+	if err == nil {
+		t.Fatalf("Expected an error for a duplicate key, got none")
+	}
+	if !strings.Contains(err.Error(), "duplicate key 'port'") {
+		t.Fatalf("Wanted a duplicate key err, got %q\n", err)
+	}
+	if !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Expected the position of both definitions, got %q\n", err)
+	}
+
+	// The same key in different blocks is not a duplicate.
+	if _, err := ParseStrict("a { port: 4222 }\nb { port: 4222 }"); err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+}
+
 func TestEnvVariable(t *testing.T) {
 	ex := map[string]any{
 		"foo": int64(22),
@@ -112,6 +145,23 @@ func TestEnvVariableString(t *testing.T) {
 	test(t, fmt.Sprintf("foo = $%s", evar), ex)
 }
 
+func TestEnvVariableList(t *testing.T) {
+	ex := map[string]any{
+		"routes": []any{"a", "b", "c"},
+	}
+	evar := "__UNIQ22__"
+	os.Setenv(evar, "a, b, c")
+	defer os.Unsetenv(evar)
+	test(t, fmt.Sprintf("routes = $%s[]", evar), ex)
+
+	// Elements are parsed like any other scalar value.
+	ex = map[string]any{
+		"ports": []any{int64(4222), int64(4223)},
+	}
+	os.Setenv(evar, "4222,4223")
+	test(t, fmt.Sprintf("ports = $%s[]", evar), ex)
+}
+
 func TestEnvVariableStringStartingWithNumber(t *testing.T) {
 	evar := "__UNIQ22__"
 	os.Setenv(evar, "3xyz")
@@ -195,6 +245,29 @@ func TestConvenientNumbers(t *testing.T) {
 	test(t, easynum, ex)
 }
 
+func TestConvenientNumbersOverflow(t *testing.T) {
+	// 9999PiB does not fit into an int64 and must not wrap to a negative number
+	_, err := Parse("overflow = 9999PiB")
+	if err == nil {
+		t.Fatalf("Expected an overflow error")
+	}
+	if !strings.Contains(err.Error(), "out of the range") {
+		t.Fatalf("Expected an out of range error, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "on line 1") {
+		t.Fatalf("Expected error to carry position, got %q", err.Error())
+	}
+
+	// Values near the limit still parse
+	m, err := Parse("max = 7EiB")
+	if err != nil {
+		t.Fatalf("Received err: %v", err)
+	}
+	if m["max"] != int64(7*1024*1024*1024*1024*1024*1024) {
+		t.Fatalf("Got wrong value for max: %v", m["max"])
+	}
+}
+
 var sample1 = `
 foo  {
   host {
@@ -218,6 +291,20 @@ func TestSample1(t *testing.T) {
 	test(t, sample1, ex)
 }
 
+func TestParseReader(t *testing.T) {
+	fromReader, err := ParseReader(strings.NewReader(sample1), "sample1")
+	if err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+	fromString, err := Parse(sample1)
+	if err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+	if !reflect.DeepEqual(fromReader, fromString) {
+		t.Fatalf("Not Equal:\nReceived: '%+v'\nExpected: '%+v'\n", fromReader, fromString)
+	}
+}
+
 var cluster = `
 cluster {
   port: 4244
@@ -357,7 +444,7 @@ authorization {
 `
 
 func TestIncludeVariablesWithChecks(t *testing.T) {
-	p, err := parse(varIncludedVariablesSample, "", true)
+	p, err := parse(varIncludedVariablesSample, "", true, false)
 	if err != nil {
 		t.Fatalf("Received err: %v\n", err)
 	}
@@ -444,7 +531,7 @@ func TestParseWithNoValuesAreInvalid(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			if _, err := parse(test.conf, "", true); err == nil {
+			if _, err := parse(test.conf, "", true, false); err == nil {
 				t.Error("expected an error")
 			} else if !strings.Contains(err.Error(), test.err) {
 				t.Errorf("expected invalid conf error, got: %v", err)
@@ -478,7 +565,7 @@ func TestParseWithNoValuesEmptyConfigsAreValid(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			if _, err := parse(test.conf, "", true); err != nil {
+			if _, err := parse(test.conf, "", true, false); err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
 		})
@@ -520,7 +607,7 @@ func TestParseWithTrailingBracketsAreValid(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			if _, err := parse(test.conf, "", true); err != nil {
+			if _, err := parse(test.conf, "", true, false); err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
 		})
@@ -589,7 +676,7 @@ func TestParseWithNoValuesIncludes(t *testing.T) {
 					}
 				}
 			}
-			if _, err := parse(test.input, f.Name(), true); err == nil {
+			if _, err := parse(test.input, f.Name(), true, false); err == nil {
 				t.Error("expected an error")
 			} else if !strings.Contains(err.Error(), test.err) || !strings.Contains(err.Error(), test.linepos) {
 				t.Errorf("expected invalid conf error, got: %v", err)
@@ -598,6 +685,68 @@ func TestParseWithNoValuesIncludes(t *testing.T) {
 	}
 }
 
+func TestIncludeGlob(t *testing.T) {
+	sdir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(sdir, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"conf.d/a.conf":  `foo = 1`,
+		"conf.d/b.conf":  `bar = "hello"`,
+		"conf.d/ignored": `baz = 3`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(sdir, name), []byte(contents), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	confFile := filepath.Join(sdir, "nats.conf")
+	input := `
+	port = 4222
+	include 'conf.d/*.conf'
+	`
+	if err := os.WriteFile(confFile, []byte(input), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ParseFile(confFile)
+	if err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+	ex := map[string]any{
+		"port": int64(4222),
+		"foo":  int64(1),
+		"bar":  "hello",
+	}
+	if !reflect.DeepEqual(m, ex) {
+		t.Fatalf("Not Equal:\nReceived: '%+v'\nExpected: '%+v'\n", m, ex)
+	}
+
+	// Errors keep pointing at the file that failed to parse
+	if err := os.WriteFile(filepath.Join(sdir, "conf.d", "c.conf"), []byte(`?????????????`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(confFile); err == nil {
+		t.Error("expected an error for the broken include file")
+	} else if !strings.Contains(err.Error(), "c.conf") {
+		t.Errorf("expected error to name the broken include file, got: %v", err)
+	}
+
+	// A pattern matching no files is an error...
+	if _, err := parse("include 'missing.d/*.conf'", confFile, false, false); err == nil {
+		t.Error("expected an error for a glob matching no files")
+	} else if !strings.Contains(err.Error(), "matched no files") {
+		t.Errorf("expected a no match error, got: %v", err)
+	}
+
+	// ...unless the include is marked optional with a '?' prefix
+	if m, err := parse("include '?missing.d/*.conf'", confFile, false, false); err != nil {
+		t.Errorf("expected an optional glob without matches to be ignored, got: %v", err)
+	} else if len(m.mapping) != 0 {
+		t.Errorf("expected an empty config, got: %+v", m.mapping)
+	}
+}
+
 func TestJSONParseCompat(t *testing.T) {
 	for _, test := range []struct {
 		name     string
@@ -740,3 +889,254 @@ func TestJSONParseCompat(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeAnnotations(t *testing.T) {
+	ex := map[string]any{
+		"port":    int64(4222),
+		"timeout": 5 * time.Second,
+		"size":    int64(1024 * 1024 * 1024),
+		"ratio":   float64(0.5),
+		"debug":   true,
+	}
+	test(t, `
+	port(int): "4222"
+	timeout(duration): "5s"
+	size(bytes): "1GB"
+	ratio(float): "0.5"
+	debug(bool): "yes"
+	`, ex)
+
+	// Values that already have the annotated type pass through unchanged.
+	test(t, `port(int): 4222; timeout(duration): "5s"; size(bytes): 1GB; ratio(float): 0.5; debug(bool): yes`, ex)
+}
+
+func TestTypeAnnotationCoercionFailure(t *testing.T) {
+	for _, conf := range []string{
+		`port(int): "not-a-number"`,
+		`timeout(duration): "5 parsecs"`,
+		`size(bytes): "huge"`,
+		`level(color): "red"`,
+	} {
+		if _, err := Parse(conf); err == nil {
+			t.Fatalf("Expected an error parsing %q", conf)
+		}
+	}
+}
+
+func TestSecretRedactionInMarshal(t *testing.T) {
+	ex := map[string]any{
+		"listen":  "127.0.0.1:4222",
+		"api_key": &Secret{"abc123"},
+		"authorization": map[string]any{
+			"user":     "derek",
+			"password": "top_secret",
+		},
+	}
+	conf := `
+		listen: "127.0.0.1:4222"
+		api_key(secret): "abc123"
+		authorization {
+			user: "derek"
+			password: "top_secret"
+		}
+	`
+	test(t, conf, ex)
+	m, err := Parse(conf)
+	if err != nil {
+		t.Fatalf("Received err: %v\n", err)
+	}
+
+	// The wrapped value stays accessible programmatically.
+	secret, ok := m["api_key"].(*Secret)
+	if !ok {
+		t.Fatalf("Expected api_key to be a *Secret, got %T", m["api_key"])
+	}
+	if secret.Value() != "abc123" {
+		t.Fatalf("Expected secret value to be accessible, got %v", secret.Value())
+	}
+
+	out := Marshal(m)
+	for _, leak := range []string{"abc123", "top_secret"} {
+		if strings.Contains(out, leak) {
+			t.Fatalf("Expected Marshal output to redact %q:\n%s", leak, out)
+		}
+	}
+	if !strings.Contains(out, "api_key: ***") {
+		t.Fatalf("Expected redacted api_key in Marshal output:\n%s", out)
+	}
+	if !strings.Contains(out, "password: ***") {
+		t.Fatalf("Expected redacted password in Marshal output:\n%s", out)
+	}
+	if !strings.Contains(out, `user: "derek"`) {
+		t.Fatalf("Expected non-secret values to be kept in Marshal output:\n%s", out)
+	}
+}
+
+func TestSecretRedactionInParseError(t *testing.T) {
+	_, err := parse(`password(int): "not-a-number"`, "", true, false)
+	if err == nil {
+		t.Fatal("Expected a coercion error for a secret key")
+	}
+	if strings.Contains(err.Error(), "not-a-number") {
+		t.Fatalf("Expected the secret value to be redacted in %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "***") || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("Expected redacted error referencing the line, got %q", err.Error())
+	}
+}
+
+func TestParseMaxNestingDepth(t *testing.T) {
+	// Nesting below the limit keeps working
+	ex := map[string]any{
+		"a": []any{[]any{[]any{int64(1)}}},
+	}
+	test(t, "a = [ [ [ 1 ] ] ]", ex)
+
+	// Nesting beyond the limit errors out gracefully instead of crashing
+	depth := MaxNestingDepth + 10
+	conf := "a = " + strings.Repeat("[ ", depth) + strings.Repeat("] ", depth)
+	_, err := Parse(conf)
+	if err == nil {
+		t.Fatalf("Expected an error parsing a configuration nested %d levels deep", depth)
+	}
+	if !strings.Contains(err.Error(), "maximum nesting depth") {
+		t.Fatalf("Expected nesting depth error, got %q", err.Error())
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := Schema{
+		"port":      nil,
+		"jetstream": Schema{"store_dir": nil, "max_mem": nil},
+		"authorization": Schema{
+			"users": Schema{"user": nil, "password": nil},
+		},
+	}
+
+	validate := func(t *testing.T, conf string) []error {
+		t.Helper()
+		p, err := parse(conf, "", true, false)
+		if err != nil {
+			t.Fatalf("Received err: %v\n", err)
+		}
+		return Validate(p.mapping, schema)
+	}
+
+	// A configuration using only known keys passes.
+	errs := validate(t, `
+	port: 4222
+	jetstream {
+	  store_dir: "/tmp/js"
+	}
+	authorization {
+	  users = [
+	    {user: alice, password: bar}
+	  ]
+	}
+	`)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+
+	// A misspelled top-level key is reported with its position.
+	errs = validate(t, `
+	port: 4222
+	jetsream {
+	  store_dir: "/tmp/js"
+	}
+	`)
+	if len(errs) != 1 {
+		t.Fatalf("Expected a single error, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "unknown key 'jetsream'") {
+		t.Fatalf("Expected unknown key error, got %q", errs[0].Error())
+	}
+	if !strings.Contains(errs[0].Error(), "on line 3") {
+		t.Fatalf("Expected error to carry position, got %q", errs[0].Error())
+	}
+
+	// Unknown keys inside nested blocks and array entries carry their path.
+	errs = validate(t, `
+	jetstream {
+	  max_memm: 1GB
+	}
+	authorization {
+	  users = [
+	    {user: alice, passwrd: bar}
+	  ]
+	}
+	`)
+	if len(errs) != 2 {
+		t.Fatalf("Expected two errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "unknown key 'authorization.users.passwrd'") {
+		t.Fatalf("Expected nested unknown key error, got %q", errs[0].Error())
+	}
+	if !strings.Contains(errs[1].Error(), "unknown key 'jetstream.max_memm'") {
+		t.Fatalf("Expected nested unknown key error, got %q", errs[1].Error())
+	}
+}
+
+func TestCheckAuthorization(t *testing.T) {
+	check := func(t *testing.T, conf string) error {
+		t.Helper()
+		p, err := parse(conf, "", true, false)
+		if err != nil {
+			t.Fatalf("Received err: %v\n", err)
+		}
+		return CheckAuthorization(p.mapping)
+	}
+
+	// Mixing a single user with a users array is rejected.
+	err := check(t, `
+	authorization {
+	  user: derek
+	  password: foo
+	  users = [
+	    {user: alice, password: bar}
+	  ]
+	}
+	`)
+	if err == nil {
+		t.Fatalf("Expected an error for mixing 'user' and 'users'")
+	}
+	if !strings.Contains(err.Error(), "'user' and a 'users' array") {
+		t.Fatalf("Expected mutually exclusive error, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "on line 5") {
+		t.Fatalf("Expected error to carry position, got %q", err.Error())
+	}
+
+	// A non-numeric timeout is rejected.
+	err = check(t, `
+	authorization {
+	  user: derek
+	  password: foo
+	  timeout: abc
+	}
+	`)
+	if err == nil {
+		t.Fatalf("Expected an error for a non-numeric timeout")
+	}
+	if !strings.Contains(err.Error(), "'timeout' should be a number") {
+		t.Fatalf("Expected timeout error, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "on line 5") {
+		t.Fatalf("Expected error to carry position, got %q", err.Error())
+	}
+
+	// A valid block and a configuration without authorization both pass.
+	if err := check(t, `
+	authorization {
+	  users = [
+	    {user: alice, password: bar}
+	  ]
+	  timeout: 2.5
+	}
+	`); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := check(t, `port: 4222`); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
@@ -370,6 +370,10 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	if err != nil {
 		log.Errorf("CostModel.ComputeAllocation: failed to build pod map: %s", err.Error())
 	}
+
+	// Drop instantaneous pods before they can poison per-minute cost math
+	pruneZeroDurationPods(podMap)
+
 	// (2) Run and apply remaining queries
 
 	// Query for the duration between start and end
@@ -661,8 +665,7 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	// a PVC, we get time running there, so this is only inaccurate
 	// for short-lived, unmounted PVs.)
 	pvMap := map[pvKey]*pv{}
-	buildPVMap(resolution, pvMap, resPVCostPerGiBHour, resPVActiveMins, resPVMeta, window)
-	applyPVBytes(pvMap, resPVBytes)
+	buildPVMap(resolution, pvMap, resPVCostPerGiBHour, resPVActiveMins, resPVMeta, resPVBytes, window)
 
 	// Build out the map of all PVCs with time running, bytes requested,
 	// and connect to the correct PV from pvMap. (If no PV exists, that
@@ -125,6 +125,22 @@ func (cm *CostModel) buildPodMap(window opencost.Window, resolution, maxBatchSiz
 	return nil
 }
 
+// pruneZeroDurationPods removes pods whose Start equals their End from the
+// given podMap. Such "instantaneous" pods carry zero minutes and would
+// otherwise produce divide-by-zero results in downstream cost computations.
+func pruneZeroDurationPods(podMap map[podKey]*pod) {
+	pruned := 0
+	for key, pod := range podMap {
+		if pod.Start.Equal(pod.End) {
+			delete(podMap, key)
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		log.Debugf("CostModel.ComputeAllocation: pruned %d zero-duration pods from pod map", pruned)
+	}
+}
+
 func applyPodResults(window opencost.Window, resolution time.Duration, podMap map[podKey]*pod, clusterStart, clusterEnd map[string]time.Time, resPods []*prom.QueryResult, ingestPodUID bool, podUIDKeyMap map[podKey][]podKey) {
 	for _, res := range resPods {
 		if len(res.Values) == 0 {
@@ -779,6 +795,116 @@ func applyNetworkAllocation(podMap map[podKey]*pod, resNetworkGiB []*prom.QueryR
 	}
 }
 
+// applyEphemeralStorageAllocation computes the cost of ephemeral storage
+// (e.g. emptyDir, local) used by each pod over the window at the given
+// per-GiB-hour rate and attributes it to the pod's allocations. The cost is
+// accumulated in ExternalCost, keeping it distinct from PV cost.
+func applyEphemeralStorageAllocation(podMap map[podKey]*pod, resEphemeralStorageGiB []*prom.QueryResult, costPerGiBHr float64, podUIDKeyMap map[podKey][]podKey) {
+	if costPerGiBHr <= 0.0 {
+		return
+	}
+
+	for _, res := range resEphemeralStorageGiB {
+		if len(res.Values) == 0 {
+			log.Warnf("CostModel.ComputeAllocation: empty ephemeral storage result")
+			continue
+		}
+
+		podKey, err := resultPodKey(res, env.GetPromClusterLabel(), "namespace")
+		if err != nil {
+			log.DedupedWarningf(10, "CostModel.ComputeAllocation: ephemeral storage query result missing field: %s", err)
+			continue
+		}
+
+		var pods []*pod
+
+		if thisPod, ok := podMap[podKey]; !ok {
+			if uidKeys, ok := podUIDKeyMap[podKey]; ok {
+				for _, uidKey := range uidKeys {
+					thisPod, ok = podMap[uidKey]
+					if ok {
+						pods = append(pods, thisPod)
+					}
+				}
+			} else {
+				continue
+			}
+		} else {
+			pods = []*pod{thisPod}
+		}
+
+		for _, thisPod := range pods {
+			for _, alloc := range thisPod.Allocations {
+				gib := res.Values[0].Value / float64(len(thisPod.Allocations))
+				hours := alloc.Minutes() / 60.0
+				alloc.ExternalCost += gib * hours * costPerGiBHr / float64(len(pods))
+			}
+		}
+	}
+}
+
+// amortizationShare selects how a one-time cost is split across allocations.
+type amortizationShare string
+
+const (
+	// amortizeByTime splits proportionally to each allocation's running time
+	amortizeByTime amortizationShare = "time"
+	// amortizeByUsage splits proportionally to each allocation's total cost
+	amortizeByUsage amortizationShare = "usage"
+)
+
+// amortizeOneTimeCost spreads a lumpy, one-off charge (e.g. a snapshot or an
+// egress burst) across every allocation in the pod map, proportionally to
+// running time or to usage. The share lands on each allocation's SharedCost,
+// and the applied total is returned so callers can record the amortization as
+// its own adjustment line.
+func amortizeOneTimeCost(podMap map[podKey]*pod, cost float64, share amortizationShare) float64 {
+	if cost <= 0.0 {
+		return 0.0
+	}
+
+	var allocs []*opencost.Allocation
+	for _, thisPod := range podMap {
+		for _, alloc := range thisPod.Allocations {
+			allocs = append(allocs, alloc)
+		}
+	}
+	if len(allocs) == 0 {
+		return 0.0
+	}
+
+	totalWeight := 0.0
+	weights := make(map[*opencost.Allocation]float64, len(allocs))
+	for _, alloc := range allocs {
+		weight := alloc.Minutes()
+		if share == amortizeByUsage {
+			weight = alloc.TotalCost()
+		}
+		if weight <= 0.0 {
+			continue
+		}
+		weights[alloc] = weight
+		totalWeight += weight
+	}
+
+	// Without any usable weight (e.g. zero-length allocations, or usage-based
+	// sharing with no cost accrued yet) fall back to an even split
+	if totalWeight == 0.0 {
+		for _, alloc := range allocs {
+			weights[alloc] = 1.0
+		}
+		totalWeight = float64(len(allocs))
+	}
+
+	applied := 0.0
+	for alloc, weight := range weights {
+		amount := cost * weight / totalWeight
+		alloc.SharedCost += amount
+		applied += amount
+	}
+	return applied
+}
+
 func resToNodeLabels(resNodeLabels []*prom.QueryResult) map[nodeKey]map[string]string {
 	nodeLabels := map[nodeKey]map[string]string{}
 
@@ -974,6 +1100,37 @@ func applyLabels(podMap map[podKey]*pod, nodeLabels map[nodeKey]map[string]strin
 	}
 }
 
+// filterPodsByLabelSelector removes pods from podMap whose allocation labels
+// do not match the given Kubernetes-style label selector (e.g. "team=infra,
+// app in (web, api)"), so downstream aggregation only sees matching
+// allocations. An empty selector keeps every pod. Labels must already have
+// been applied to the allocations (see applyLabels).
+func filterPodsByLabelSelector(podMap map[podKey]*pod, selector string) error {
+	if selector == "" {
+		return nil
+	}
+
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid label selector %q: %s", selector, err)
+	}
+
+	for key, pod := range podMap {
+		matches := false
+		for _, alloc := range pod.Allocations {
+			if sel.Matches(labels.Set(alloc.Properties.Labels)) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			delete(podMap, key)
+		}
+	}
+
+	return nil
+}
+
 func applyAnnotations(podMap map[podKey]*pod, namespaceAnnotations map[string]map[string]string, podAnnotations map[podKey]map[string]string) {
 	for key, pod := range podMap {
 		for _, alloc := range pod.Allocations {
@@ -1409,9 +1566,7 @@ func applyLoadBalancersToPods(window opencost.Window, podMap map[podKey]*pod, lb
 		allocs, ok := allocsByService[sKey]
 		// if there are no allocations using the service, add its cost to the Unmounted pod for its cluster
 		if !ok {
-			pod := getUnmountedPodForCluster(window, podMap, sKey.Cluster)
-			pod.Allocations[opencost.UnmountedSuffix].LoadBalancerCost += lb.TotalCost
-			pod.Allocations[opencost.UnmountedSuffix].Properties.Services = append(pod.Allocations[opencost.UnmountedSuffix].Properties.Services, sKey.Service)
+			addUnattributedLoadBalancerCost(window, podMap, sKey, lb)
 		}
 		// Add portion of load balancing cost to each allocation
 		// proportional to the total number of hours allocations used the load balancer
@@ -1469,9 +1624,7 @@ func applyLoadBalancersToPods(window opencost.Window, podMap map[podKey]*pod, lb
 
 		// If there was no overlap apply to Unmounted pod
 		if len(allocHours) == 0 {
-			pod := getUnmountedPodForCluster(window, podMap, sKey.Cluster)
-			pod.Allocations[opencost.UnmountedSuffix].LoadBalancerCost += lb.TotalCost
-			pod.Allocations[opencost.UnmountedSuffix].Properties.Services = append(pod.Allocations[opencost.UnmountedSuffix].Properties.Services, sKey.Service)
+			addUnattributedLoadBalancerCost(window, podMap, sKey, lb)
 		}
 	}
 }
@@ -1797,7 +1950,7 @@ func (cm *CostModel) getNodePricing(nodeMap map[nodeKey]*nodePricing, nodeKey no
 
 /* PV/PVC Helpers */
 
-func buildPVMap(resolution time.Duration, pvMap map[pvKey]*pv, resPVCostPerGiBHour, resPVActiveMins, resPVMeta []*prom.QueryResult, window opencost.Window) {
+func buildPVMap(resolution time.Duration, pvMap map[pvKey]*pv, resPVCostPerGiBHour, resPVActiveMins, resPVMeta, resPVBytes []*prom.QueryResult, window opencost.Window) {
 	for _, result := range resPVActiveMins {
 		key, err := resultPVKey(result, env.GetPromClusterLabel(), "persistentvolume")
 		if err != nil {
@@ -1853,24 +2006,22 @@ func buildPVMap(resolution time.Duration, pvMap map[pvKey]*pv, resPVCostPerGiBHo
 		}
 
 	}
-}
 
-func applyPVBytes(pvMap map[pvKey]*pv, resPVBytes []*prom.QueryResult) {
-	for _, res := range resPVBytes {
-		key, err := resultPVKey(res, env.GetPromClusterLabel(), "persistentvolume")
+	for _, result := range resPVBytes {
+		key, err := resultPVKey(result, env.GetPromClusterLabel(), "persistentvolume")
 		if err != nil {
 			log.Warnf("CostModel.ComputeAllocation: pv bytes query result missing field: %s", err)
 			continue
 		}
 
 		if _, ok := pvMap[key]; !ok {
-			log.Warnf("CostModel.ComputeAllocation: pv bytes result for missing pv: %s", err)
+			log.Warnf("CostModel.ComputeAllocation: pv bytes result for missing pv: %s", key)
 			continue
 		}
 
-		pvBytesUsed := res.Values[0].Value
-		if pvBytesUsed < PV_USAGE_SANITY_LIMIT_BYTES {
-			pvMap[key].Bytes = pvBytesUsed
+		pvBytes := timeWeightedMeanBytes(result, resolution)
+		if pvBytes < PV_USAGE_SANITY_LIMIT_BYTES {
+			pvMap[key].Bytes = pvBytes
 		} else {
 			pvMap[key].Bytes = 0
 			log.Warnf("PV usage exceeds sanity limit, clamping to zero")
@@ -1878,6 +2029,37 @@ func applyPVBytes(pvMap map[pvKey]*pv, resPVBytes []*prom.QueryResult) {
 	}
 }
 
+// timeWeightedMeanBytes computes the time-weighted mean of a PV capacity
+// series. Each sample is weighted by the duration until the next sample, with
+// the final sample covering one resolution, so a PV resized mid-window
+// contributes each capacity proportionally to how long it was in effect.
+func timeWeightedMeanBytes(result *prom.QueryResult, resolution time.Duration) float64 {
+	if len(result.Values) == 0 {
+		return 0.0
+	}
+	if len(result.Values) == 1 {
+		return result.Values[0].Value
+	}
+
+	weighted := 0.0
+	totalSeconds := 0.0
+	for i, v := range result.Values {
+		seconds := resolution.Seconds()
+		if i < len(result.Values)-1 {
+			seconds = result.Values[i+1].Timestamp - v.Timestamp
+		}
+		if seconds <= 0.0 {
+			continue
+		}
+		weighted += v.Value * seconds
+		totalSeconds += seconds
+	}
+	if totalSeconds == 0.0 {
+		return result.Values[0].Value
+	}
+	return weighted / totalSeconds
+}
+
 func buildPVCMap(resolution time.Duration, pvcMap map[pvcKey]*pvc, pvMap map[pvKey]*pv, resPVCInfo []*prom.QueryResult, window opencost.Window) {
 	for _, res := range resPVCInfo {
 		cluster, err := res.GetString(env.GetPromClusterLabel())
@@ -2179,6 +2361,21 @@ func applyUnmountedPVCs(window opencost.Window, podMap map[podKey]*pod, pvcMap m
 /* Helper Helpers */
 
 // getUnmountedPodForCluster retrieve the unmounted pod for a cluster and create it if it does not exist
+// addUnattributedLoadBalancerCost accumulates the cost of a load balancer
+// that no allocation uses into the unmounted pod for its cluster, merging the
+// service into the allocation's Services list without duplicating entries.
+func addUnattributedLoadBalancerCost(window opencost.Window, podMap map[podKey]*pod, sKey serviceKey, lb *lbCost) {
+	thisPod := getUnmountedPodForCluster(window, podMap, sKey.Cluster)
+	alloc := thisPod.Allocations[opencost.UnmountedSuffix]
+	alloc.LoadBalancerCost += lb.TotalCost
+	for _, service := range alloc.Properties.Services {
+		if service == sKey.Service {
+			return
+		}
+	}
+	alloc.Properties.Services = append(alloc.Properties.Services, sKey.Service)
+}
+
 func getUnmountedPodForCluster(window opencost.Window, podMap map[podKey]*pod, cluster string) *pod {
 	container := opencost.UnmountedSuffix
 	podName := opencost.UnmountedSuffix
@@ -2255,14 +2452,19 @@ func calculateStartAndEnd(result *prom.QueryResult, resolution time.Duration, wi
 	// E.g. avg(kube_pod_container_status_running{}) by (pod, namespace)[1h:1m]
 	// with time=01:00:00 will return, for a pod running the entire time,
 	// 61 timestamps where the first is 00:00:00 and the last is 01:00:00.
-	s := time.Unix(int64(result.Values[0].Timestamp), 0).UTC()
-	e := time.Unix(int64(result.Values[len(result.Values)-1].Timestamp), 0).UTC()
+	// Timestamps are float seconds; convert via milliseconds so that
+	// sub-second precision (e.g. the 7.5s half-offset of a 15s resolution)
+	// survives the conversion.
+	s := time.UnixMilli(int64(result.Values[0].Timestamp * 1000)).UTC()
+	e := time.UnixMilli(int64(result.Values[len(result.Values)-1].Timestamp * 1000)).UTC()
 
 	// The only corner-case here is what to do if you only get one timestamp.
 	// This dilemma still requires the use of the resolution, and can be
 	// clamped using the window. In this case, we want to honor the existence
 	// of the pod by giving "one resolution" worth of duration, half on each
-	// side of the given timestamp.
+	// side of the given timestamp. The resolution may be shorter than one
+	// minute (e.g. a 15s scrape interval), so the half-offset is computed in
+	// Duration precision rather than whole minutes or seconds.
 	if s.Equal(e) {
 		s = s.Add(-1 * resolution / time.Duration(2))
 		e = e.Add(resolution / time.Duration(2))
@@ -2276,3 +2478,42 @@ func calculateStartAndEnd(result *prom.QueryResult, resolution time.Duration, wi
 
 	return s, e
 }
+
+/* Blended Cost Helpers */
+
+// blendedCPURate computes a blended per-CPU-core-hour rate for the given
+// usage, consuming reserved capacity first at its committed rate and charging
+// the remainder at the on-demand rate. With no usage, the on-demand rate is
+// returned unchanged.
+func blendedCPURate(usedCPUCoreHours, reservedCPUCoreHours, reservedRate, onDemandRate float64) float64 {
+	if usedCPUCoreHours <= 0.0 {
+		return onDemandRate
+	}
+	reservedUsed := math.Min(usedCPUCoreHours, reservedCPUCoreHours)
+	onDemandUsed := usedCPUCoreHours - reservedUsed
+	totalCost := reservedUsed*reservedRate + onDemandUsed*onDemandRate
+	return totalCost / usedCPUCoreHours
+}
+
+// applyBlendedCPUCost recomputes the CPU cost of every allocation using a
+// blended rate derived from the total CPU usage across the pod map and the
+// given reserved-capacity commitment.
+func applyBlendedCPUCost(podMap map[podKey]*pod, reservedCPUCoreHours, reservedRate, onDemandRate float64) {
+	if reservedCPUCoreHours <= 0.0 {
+		return
+	}
+
+	totalCPUCoreHours := 0.0
+	for _, pod := range podMap {
+		for _, alloc := range pod.Allocations {
+			totalCPUCoreHours += alloc.CPUCoreHours
+		}
+	}
+
+	rate := blendedCPURate(totalCPUCoreHours, reservedCPUCoreHours, reservedRate, onDemandRate)
+	for _, pod := range podMap {
+		for _, alloc := range pod.Allocations {
+			alloc.CPUCost = alloc.CPUCoreHours * rate
+		}
+	}
+}
@@ -2,6 +2,7 @@ package costmodel
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -354,7 +355,7 @@ func TestBuildPVMap(t *testing.T) {
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
 			pvMap := make(map[pvKey]*pv)
-			buildPVMap(testCase.resolution, pvMap, testCase.resultsPVCostPerGiBHour, testCase.resultsActiveMinutes, []*prom.QueryResult{}, window)
+			buildPVMap(testCase.resolution, pvMap, testCase.resultsPVCostPerGiBHour, testCase.resultsActiveMinutes, []*prom.QueryResult{}, []*prom.QueryResult{}, window)
 			if len(pvMap) != len(testCase.expected) {
 				t.Errorf("pv map does not have the expected length %d : %d", len(pvMap), len(testCase.expected))
 			}
@@ -364,7 +365,7 @@ func TestBuildPVMap(t *testing.T) {
 				if !ok {
 					t.Errorf("pv map is missing key %s", thisPVKey)
 				}
-				if !actualPV.equal(expectedPV) {
+				if !actualPV.equalWithin(expectedPV, 1e-9) {
 					t.Errorf("pv does not match with key %s: %s != %s", thisPVKey, opencost.NewClosedWindow(actualPV.Start, actualPV.End), opencost.NewClosedWindow(expectedPV.Start, expectedPV.End))
 				}
 			}
@@ -372,6 +373,41 @@ func TestBuildPVMap(t *testing.T) {
 	}
 }
 
+func TestPruneZeroDurationPods(t *testing.T) {
+	zeroDurationKey := podKey{
+		namespaceKey: namespaceKey{
+			Cluster:   "cluster1",
+			Namespace: "namespace1",
+		},
+		Pod: "podZeroDuration",
+	}
+
+	podMap := map[podKey]*pod{}
+	for key, pod := range podMap1 {
+		podMap[key] = pod
+	}
+	podMap[zeroDurationKey] = &pod{
+		Window: window.Clone(),
+		Start:  time.Date(2020, 6, 16, 12, 0, 0, 0, time.UTC),
+		End:    time.Date(2020, 6, 16, 12, 0, 0, 0, time.UTC),
+		Key:    zeroDurationKey,
+	}
+
+	pruneZeroDurationPods(podMap)
+
+	if _, ok := podMap[zeroDurationKey]; ok {
+		t.Errorf("expected zero-duration pod %s to be pruned", zeroDurationKey)
+	}
+	if len(podMap) != len(podMap1) {
+		t.Errorf("expected %d pods to remain, got %d", len(podMap1), len(podMap))
+	}
+	for key := range podMap1 {
+		if _, ok := podMap[key]; !ok {
+			t.Errorf("expected pod %s to remain in pod map", key)
+		}
+	}
+}
+
 /* Helper Helpers */
 
 func TestGetUnmountedPodForCluster(t *testing.T) {
@@ -555,6 +591,90 @@ func TestCalculateStartAndEnd(t *testing.T) {
 				},
 			},
 		},
+		"30 second resolution, 2 minute window": {
+			resolution:    time.Second * 30,
+			expectedStart: windowStart.Add(time.Minute * 15),
+			expectedEnd:   windowStart.Add(time.Minute * 17),
+			result: &prom.QueryResult{
+				Values: []*util.Vector{
+					{
+						Timestamp: startFloat + (minute * 15),
+					},
+					{
+						Timestamp: startFloat + (minute * 15) + 30,
+					},
+					{
+						Timestamp: startFloat + (minute * 16),
+					},
+					{
+						Timestamp: startFloat + (minute * 16) + 30,
+					},
+					{
+						Timestamp: startFloat + (minute * 17),
+					},
+				},
+			},
+		},
+		"30 second resolution, 30 second window": {
+			resolution:    time.Second * 30,
+			expectedStart: windowStart.Add(time.Minute * 15).Add(-time.Second * 15),
+			expectedEnd:   windowStart.Add(time.Minute * 15).Add(time.Second * 15),
+			result: &prom.QueryResult{
+				Values: []*util.Vector{
+					{
+						Timestamp: startFloat + (minute * 15),
+					},
+				},
+			},
+		},
+		"15 second resolution, 1 minute window": {
+			resolution:    time.Second * 15,
+			expectedStart: windowStart.Add(time.Minute * 15),
+			expectedEnd:   windowStart.Add(time.Minute * 16),
+			result: &prom.QueryResult{
+				Values: []*util.Vector{
+					{
+						Timestamp: startFloat + (minute * 15),
+					},
+					{
+						Timestamp: startFloat + (minute * 15) + 15,
+					},
+					{
+						Timestamp: startFloat + (minute * 15) + 30,
+					},
+					{
+						Timestamp: startFloat + (minute * 15) + 45,
+					},
+					{
+						Timestamp: startFloat + (minute * 16),
+					},
+				},
+			},
+		},
+		"15 second resolution, 15 second window": {
+			resolution:    time.Second * 15,
+			expectedStart: windowStart.Add(time.Minute * 15).Add(-time.Millisecond * 7500),
+			expectedEnd:   windowStart.Add(time.Minute * 15).Add(time.Millisecond * 7500),
+			result: &prom.QueryResult{
+				Values: []*util.Vector{
+					{
+						Timestamp: startFloat + (minute * 15),
+					},
+				},
+			},
+		},
+		"15 second resolution, 15 second window, at window start": {
+			resolution:    time.Second * 15,
+			expectedStart: windowStart,
+			expectedEnd:   windowStart.Add(time.Millisecond * 7500),
+			result: &prom.QueryResult{
+				Values: []*util.Vector{
+					{
+						Timestamp: startFloat,
+					},
+				},
+			},
+		},
 		"1 minute resolution, 1 minute window, at window start": {
 			resolution:    time.Minute,
 			expectedStart: windowStart,
@@ -581,3 +701,383 @@ func TestCalculateStartAndEnd(t *testing.T) {
 		})
 	}
 }
+
+func TestBlendedCPURate(t *testing.T) {
+	const (
+		reservedRate = 0.02
+		onDemandRate = 0.05
+	)
+
+	testCases := map[string]struct {
+		used     float64
+		reserved float64
+		expected float64
+	}{
+		"usage below reservation pays the reserved rate": {
+			used:     1.0,
+			reserved: 2.0,
+			expected: reservedRate,
+		},
+		"usage above reservation blends both rates": {
+			used:     4.0,
+			reserved: 2.0,
+			// 2 core-hours at the reserved rate, 2 at on-demand
+			expected: (2.0*reservedRate + 2.0*onDemandRate) / 4.0,
+		},
+		"no usage falls back to the on-demand rate": {
+			used:     0.0,
+			reserved: 2.0,
+			expected: onDemandRate,
+		},
+		"no reservation pays the on-demand rate": {
+			used:     4.0,
+			reserved: 0.0,
+			expected: onDemandRate,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rate := blendedCPURate(tc.used, tc.reserved, reservedRate, onDemandRate)
+			if math.Abs(rate-tc.expected) > 1e-9 {
+				t.Errorf("expected blended rate %f; got %f", tc.expected, rate)
+			}
+		})
+	}
+}
+
+func TestApplyBlendedCPUCost(t *testing.T) {
+	const (
+		reservedRate = 0.02
+		onDemandRate = 0.05
+	)
+
+	podMap := map[podKey]*pod{
+		podKey1: {
+			Key: podKey1,
+			Allocations: map[string]*opencost.Allocation{
+				"container1": {CPUCoreHours: 3.0},
+			},
+		},
+		podKey2: {
+			Key: podKey2,
+			Allocations: map[string]*opencost.Allocation{
+				"container2": {CPUCoreHours: 1.0},
+			},
+		},
+	}
+
+	// Total usage is 4.0 core-hours against a 2.0 core-hour reservation
+	applyBlendedCPUCost(podMap, 2.0, reservedRate, onDemandRate)
+
+	rate := (2.0*reservedRate + 2.0*onDemandRate) / 4.0
+	if cost := podMap[podKey1].Allocations["container1"].CPUCost; math.Abs(cost-3.0*rate) > 1e-9 {
+		t.Errorf("expected CPU cost %f; got %f", 3.0*rate, cost)
+	}
+	if cost := podMap[podKey2].Allocations["container2"].CPUCost; math.Abs(cost-1.0*rate) > 1e-9 {
+		t.Errorf("expected CPU cost %f; got %f", 1.0*rate, cost)
+	}
+}
+
+func TestApplyEphemeralStorageAllocation(t *testing.T) {
+	const costPerGiBHr = 0.004
+
+	newAlloc := func() *opencost.Allocation {
+		return &opencost.Allocation{
+			Window: window.Clone(),
+			Start:  windowStart,
+			End:    windowEnd,
+			PVs: opencost.PVAllocations{
+				{Cluster: "cluster1", Name: "pv1"}: {
+					ByteHours: 10 * Gi * 24.0,
+					Cost:      1.2,
+				},
+			},
+		}
+	}
+
+	podMap := map[podKey]*pod{
+		podKey1: {
+			Key:         podKey1,
+			Allocations: map[string]*opencost.Allocation{"container1": newAlloc()},
+		},
+	}
+
+	resEphemeralStorageGiB := []*prom.QueryResult{
+		{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"pod":        "pod1",
+			},
+			Values: []*util.Vector{
+				{
+					// average GiB of ephemeral storage used over the window
+					Value: 2.0,
+				},
+			},
+		},
+	}
+
+	applyEphemeralStorageAllocation(podMap, resEphemeralStorageGiB, costPerGiBHr, nil)
+
+	alloc := podMap[podKey1].Allocations["container1"]
+	expected := 2.0 * 24.0 * costPerGiBHr
+	if math.Abs(alloc.ExternalCost-expected) > 1e-9 {
+		t.Errorf("expected ephemeral cost %f; got %f", expected, alloc.ExternalCost)
+	}
+
+	// The PV cost must remain untouched by the ephemeral accounting
+	if pvCost := alloc.PVCost(); pvCost != 1.2 {
+		t.Errorf("expected PV cost 1.2; got %f", pvCost)
+	}
+}
+
+func TestFilterPodsByLabelSelector(t *testing.T) {
+	newPodMap := func() map[podKey]*pod {
+		return map[podKey]*pod{
+			podKey1: {
+				Key: podKey1,
+				Allocations: map[string]*opencost.Allocation{
+					"container1": {
+						Properties: &opencost.AllocationProperties{
+							Labels: map[string]string{"team": "infra", "app": "web"},
+						},
+					},
+				},
+			},
+			podKey2: {
+				Key: podKey2,
+				Allocations: map[string]*opencost.Allocation{
+					"container2": {
+						Properties: &opencost.AllocationProperties{
+							Labels: map[string]string{"team": "payments", "app": "api"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// Equality selector keeps only the matching pod
+	podMap := newPodMap()
+	if err := filterPodsByLabelSelector(podMap, "team=infra"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := podMap[podKey1]; !ok {
+		t.Errorf("expected pod %s to be kept", podKey1)
+	}
+	if _, ok := podMap[podKey2]; ok {
+		t.Errorf("expected pod %s to be filtered out", podKey2)
+	}
+
+	// Set-based selector matches both pods
+	podMap = newPodMap()
+	if err := filterPodsByLabelSelector(podMap, "app in (web, api)"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(podMap) != 2 {
+		t.Errorf("expected both pods to be kept; got %d", len(podMap))
+	}
+
+	// Empty selector keeps everything
+	podMap = newPodMap()
+	if err := filterPodsByLabelSelector(podMap, ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(podMap) != 2 {
+		t.Errorf("expected both pods to be kept; got %d", len(podMap))
+	}
+
+	// A malformed selector surfaces an error
+	if err := filterPodsByLabelSelector(newPodMap(), "team in ("); err == nil {
+		t.Errorf("expected an error for a malformed selector")
+	}
+}
+
+func TestBuildPVMapResizedPV(t *testing.T) {
+	resolution := time.Hour * 6
+
+	resultsActiveMinutes := []*prom.QueryResult{
+		{
+			Metric: map[string]interface{}{
+				"cluster_id":       "cluster1",
+				"persistentvolume": "pv1",
+			},
+			Values: []*util.Vector{
+				{
+					Timestamp: startFloat,
+				},
+				{
+					Timestamp: startFloat + (hour * 6),
+				},
+				{
+					Timestamp: startFloat + (hour * 12),
+				},
+				{
+					Timestamp: startFloat + (hour * 18),
+				},
+			},
+		},
+	}
+
+	// The PV doubles in capacity halfway through the window, so the
+	// time-weighted mean is 150Gi rather than the first or last sample
+	resultsPVBytes := []*prom.QueryResult{
+		{
+			Metric: map[string]interface{}{
+				"cluster_id":       "cluster1",
+				"persistentvolume": "pv1",
+			},
+			Values: []*util.Vector{
+				{
+					Timestamp: startFloat,
+					Value:     100 * Gi,
+				},
+				{
+					Timestamp: startFloat + (hour * 6),
+					Value:     100 * Gi,
+				},
+				{
+					Timestamp: startFloat + (hour * 12),
+					Value:     200 * Gi,
+				},
+				{
+					Timestamp: startFloat + (hour * 18),
+					Value:     200 * Gi,
+				},
+			},
+		},
+	}
+
+	pvMap := make(map[pvKey]*pv)
+	buildPVMap(resolution, pvMap, []*prom.QueryResult{}, resultsActiveMinutes, []*prom.QueryResult{}, resultsPVBytes, window)
+
+	key := pvKey{Cluster: "cluster1", PersistentVolume: "pv1"}
+	actual, ok := pvMap[key]
+	if !ok {
+		t.Fatalf("pv map is missing key %s", key)
+	}
+	if actual.Bytes != 150*Gi {
+		t.Errorf("pv bytes do not match: expected %f; got %f", 150*Gi, actual.Bytes)
+	}
+}
+
+func TestAddUnattributedLoadBalancerCost(t *testing.T) {
+	podMap := map[podKey]*pod{}
+	lbMap := map[serviceKey]*lbCost{
+		{Cluster: "cluster1", Namespace: "dangling", Service: "lb1"}: {
+			TotalCost: 0.25,
+			Start:     windowStart,
+			End:       windowEnd,
+		},
+		{Cluster: "cluster1", Namespace: "dangling", Service: "lb2"}: {
+			TotalCost: 0.50,
+			Start:     windowStart,
+			End:       windowEnd,
+		},
+	}
+
+	// Neither load balancer has allocations using it, so both costs land on
+	// the cluster's single unmounted pod
+	applyLoadBalancersToPods(window, podMap, lbMap, map[serviceKey][]*opencost.Allocation{})
+
+	thisPod, ok := podMap[getUnmountedPodKey("cluster1")]
+	if !ok {
+		t.Fatalf("expected an unmounted pod for cluster1")
+	}
+	alloc := thisPod.Allocations[opencost.UnmountedSuffix]
+	if alloc.LoadBalancerCost != 0.75 {
+		t.Errorf("load balancer cost does not match: expected %f; got %f", 0.75, alloc.LoadBalancerCost)
+	}
+	services := alloc.Properties.Services
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %v", services)
+	}
+
+	// Applying the same dangling service again sums its cost without
+	// duplicating the service entry
+	applyLoadBalancersToPods(window, podMap, map[serviceKey]*lbCost{
+		{Cluster: "cluster1", Namespace: "dangling", Service: "lb1"}: {
+			TotalCost: 0.25,
+			Start:     windowStart,
+			End:       windowEnd,
+		},
+	}, map[serviceKey][]*opencost.Allocation{})
+	if alloc.LoadBalancerCost != 1.00 {
+		t.Errorf("load balancer cost does not match: expected %f; got %f", 1.00, alloc.LoadBalancerCost)
+	}
+	if len(alloc.Properties.Services) != 2 {
+		t.Errorf("expected services not to be duplicated, got %v", alloc.Properties.Services)
+	}
+}
+
+func TestAmortizeOneTimeCost(t *testing.T) {
+	newTestPod := func(name string) *pod {
+		return &pod{
+			Window: window.Clone(),
+			Start:  windowStart,
+			End:    windowEnd,
+			Key:    newPodKey("cluster1", "namespace1", name),
+			Allocations: map[string]*opencost.Allocation{
+				"container1": {
+					Name:   name,
+					Window: window.Clone(),
+					Start:  windowStart,
+					End:    windowEnd,
+				},
+			},
+		}
+	}
+	podMap := map[podKey]*pod{
+		newPodKey("cluster1", "namespace1", "pod1"): newTestPod("pod1"),
+		newPodKey("cluster1", "namespace1", "pod2"): newTestPod("pod2"),
+	}
+
+	// A snapshot cost amortized by time over two allocations that ran the
+	// whole day is split evenly, and the applied total matches the charge
+	const snapshotCost = 10.0
+	applied := amortizeOneTimeCost(podMap, snapshotCost, amortizeByTime)
+	if math.Abs(applied-snapshotCost) > 1e-9 {
+		t.Errorf("expected applied total %f; got %f", snapshotCost, applied)
+	}
+	for key, thisPod := range podMap {
+		alloc := thisPod.Allocations["container1"]
+		if math.Abs(alloc.SharedCost-snapshotCost/2) > 1e-9 {
+			t.Errorf("expected even share %f for %s; got %f", snapshotCost/2, key, alloc.SharedCost)
+		}
+	}
+
+	// A non-positive cost is a no-op
+	if applied := amortizeOneTimeCost(podMap, 0.0, amortizeByTime); applied != 0.0 {
+		t.Errorf("expected no cost applied, got %f", applied)
+	}
+}
+
+func TestPVEqualWithin(t *testing.T) {
+	base := &pv{
+		Start:          windowStart,
+		End:            windowEnd,
+		Bytes:          100 * Gi,
+		CostPerGiBHour: 0.03,
+		Cluster:        "cluster1",
+		Name:           "pv1",
+	}
+
+	// Float noise within the tolerance still compares equal
+	noisy := base.clone()
+	noisy.CostPerGiBHour += 1e-12
+	if base.equal(noisy) {
+		t.Errorf("exact equal should reject float noise")
+	}
+	if !base.equalWithin(noisy, 1e-9) {
+		t.Errorf("equalWithin should tolerate float noise")
+	}
+
+	// Differences beyond the tolerance are rejected
+	different := base.clone()
+	different.Bytes += Gi
+	if base.equalWithin(different, 1e-9) {
+		t.Errorf("equalWithin should reject a real difference")
+	}
+}
@@ -2,6 +2,7 @@ package costmodel
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/opencost/opencost/core/pkg/opencost"
@@ -186,6 +187,46 @@ func (p *pv) equal(that *pv) bool {
 	return true
 }
 
+// equalWithin is as equal, but compares Bytes, CostPerGiBHour, and the window
+// bounds within the given tolerance. Costs accumulated from Prometheus series
+// carry floating-point noise, so exact comparison is reserved for identity
+// checks while tests use a small epsilon.
+func (p *pv) equalWithin(that *pv, epsilon float64) bool {
+	if p == nil {
+		return that == nil
+	}
+
+	maxDelta := time.Duration(epsilon * float64(time.Second))
+	if d := p.Start.Sub(that.Start); d < -maxDelta || d > maxDelta {
+		return false
+	}
+	if d := p.End.Sub(that.End); d < -maxDelta || d > maxDelta {
+		return false
+	}
+
+	if math.Abs(p.Bytes-that.Bytes) > epsilon {
+		return false
+	}
+
+	if math.Abs(p.CostPerGiBHour-that.CostPerGiBHour) > epsilon {
+		return false
+	}
+
+	if p.Cluster != that.Cluster {
+		return false
+	}
+
+	if p.Name != that.Name {
+		return false
+	}
+
+	if p.StorageClass != that.StorageClass {
+		return false
+	}
+
+	return true
+}
+
 // String returns a string representation of the pv
 func (p *pv) String() string {
 	if p == nil {
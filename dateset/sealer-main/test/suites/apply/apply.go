@@ -88,7 +88,10 @@ func LoadPluginFromDisk(clusterFilePath string) []v1.Plugin {
 	return plugins.([]v1.Plugin)
 }
 
-func GenerateClusterfile(clusterfile string) {
+// ComposeClusterfile assembles the cluster, plugins and configs referenced by
+// clusterfile into one multi-doc YAML, with the templated LABEL/HOSTNAME/TAINT
+// plugin data already rendered.
+func ComposeClusterfile(clusterfile string) []byte {
 	fp := GetRawConfigPluginFilePath()
 	cluster := LoadClusterFileFromDisk(clusterfile)
 	//convert clusterfilev1 to clusterfilev2
@@ -136,10 +139,20 @@ func GenerateClusterfile(clusterfile string) {
 		testhelper.CheckErr(err)
 		appendData = append(appendData, []byte("---\n"), data)
 	}
-	err = os.NewCommonWriter(clusterfile).WriteFile(bytes.Join(appendData, []byte("")))
+	return bytes.Join(appendData, []byte(""))
+}
+
+func GenerateClusterfile(clusterfile string) {
+	err := os.NewCommonWriter(clusterfile).WriteFile(ComposeClusterfile(clusterfile))
 	testhelper.CheckErr(err)
 }
 
+// DryRunClusterfile prints the composed Clusterfile to stdout without applying
+// it, so the generated labels, hostnames and taints can be audited beforehand.
+func DryRunClusterfile(clusterfile string) {
+	fmt.Println(string(ComposeClusterfile(clusterfile)))
+}
+
 func SealerDeleteCmd(clusterFile string) string {
 	return fmt.Sprintf("%s delete -f %s --force -d", settings.DefaultSealerBin, clusterFile)
 }
@@ -156,6 +169,10 @@ func SealerApplyCmd(clusterFile string) string {
 	return fmt.Sprintf("%s apply -f %s --force -d", settings.DefaultSealerBin, clusterFile)
 }
 
+func SealerApplyDryRunCmd(clusterFile string) string {
+	return fmt.Sprintf("%s apply -f %s --dry-run -d", settings.DefaultSealerBin, clusterFile)
+}
+
 func SealerRunCmd(masters, nodes, passwd string, provider string) string {
 	if masters != "" {
 		masters = fmt.Sprintf("-m %s", masters)
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"volcano.sh/volcano/pkg/kube"
+)
+
+const (
+	defaultSchedulerName   = "volcano"
+	defaultSchedulerPeriod = time.Second
+	defaultQueue           = "default"
+	defaultListenAddress   = ":8080"
+
+	defaultHealthzAddress = ":11251"
+
+	defaultQPS   = 2000.0
+	defaultBurst = 2000
+
+	// Default parameters to control the number of feasible nodes to find and score
+	defaultMinPercentageOfNodesToFind = 5
+	defaultMinNodesToFind             = 100
+	defaultPercentageOfNodesToFind    = 0
+
+	// defaultQueueAllocateTimeout disables the per-queue allocate time budget
+	defaultQueueAllocateTimeout = 0
+
+	defaultLockObjectNamespace = "volcano-system"
+
+	defaultNodeWorkers = 20
+)
+
+// ServerOption is the main context object for the controller manager.
+type ServerOption struct {
+	KubeClientOptions    kube.ClientOptions
+	CertFile             string
+	KeyFile              string
+	CaCertFile           string
+	CertData             []byte
+	KeyData              []byte
+	CaCertData           []byte
+	SchedulerNames       []string
+	SchedulerConf        string
+	SchedulePeriod       time.Duration
+	EnableLeaderElection bool
+	LockObjectNamespace  string
+	DefaultQueue         string
+	PrintVersion         bool
+	EnableMetrics        bool
+	EnablePprof          bool
+	DisableHealthz       bool
+	HealthzBindAddress   string
+	EnableCacheDumper    bool
+	NodeSelector         []string
+	EnableWorkerThreads  bool
+	WorkerThreads        uint32
+
+	// vc-scheduler will load (not activate) custom plugins which are in this directory
+	PluginsDir string
+
+	// Socket address where the metrics server is listening on
+	ListenAddress string
+
+	// The percentage of nodes that would be scored in each scheduling cycle; if <= 0, an adaptive percentage will be calculated
+	PercentageOfNodesToFind int32
+	// The minimal percentage of nodes that would be scored in each scheduling cycle
+	MinPercentageOfNodesToFind int32
+	// The minimal number of nodes that would be scored in each scheduling cycle
+	MinNodesToFind int32
+
+	// QueueAllocateTimeout bounds the wall-clock time the allocate action may
+	// spend on a single queue within one scheduling cycle; zero means no limit
+	QueueAllocateTimeout time.Duration
+
+	NodeWorkerThreads uint32
+}
+
+// ServerOpts server options.
+var ServerOpts *ServerOption
+
+// NewServerOption creates a new CMServer with a default config.
+func NewServerOption() *ServerOption {
+	return &ServerOption{}
+}
+
+// AddFlags adds flags for a specific CMServer to the specified FlagSet.
+func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.KubeClientOptions.Master, "master", s.KubeClientOptions.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
+	fs.StringVar(&s.KubeClientOptions.KubeConfig, "kubeconfig", s.KubeClientOptions.KubeConfig, "Path to kubeconfig file with authorization and master location information")
+	fs.Float32Var(&s.KubeClientOptions.QPS, "kube-api-qps", defaultQPS, "QPS to use while talking with kubernetes apiserver")
+	fs.IntVar(&s.KubeClientOptions.Burst, "kube-api-burst", defaultBurst, "Burst to use while talking with kubernetes apiserver")
+
+	fs.StringVar(&s.CertFile, "tls-cert-file", s.CertFile, "File containing the default x509 Certificate for HTTPS. (CA cert, if any, concatenated "+
+		"after server cert).")
+	fs.StringVar(&s.KeyFile, "tls-private-key-file", s.KeyFile, "File containing the default x509 private key matching --tls-cert-file.")
+	fs.StringVar(&s.CaCertFile, "ca-cert-file", s.CaCertFile, "File containing the x509 Certificate for HTTPS.")
+	fs.StringArrayVar(&s.SchedulerNames, "scheduler-name", []string{defaultSchedulerName}, "vc-scheduler will handle pods whose .spec.SchedulerName is same as scheduler-name")
+	fs.StringVar(&s.SchedulerConf, "scheduler-conf", "", "The absolute path of scheduler configuration file")
+	fs.DurationVar(&s.SchedulePeriod, "schedule-period", defaultSchedulerPeriod, "The period between each scheduling cycle")
+	fs.StringVar(&s.DefaultQueue, "default-queue", defaultQueue, "The default queue name of the job")
+	fs.BoolVar(&s.EnableLeaderElection, "leader-elect", s.EnableLeaderElection,
+		"Start a leader election client and gain leadership before "+
+			"executing the main loop. Enable this when running replicated vc-scheduler for high availability; it is enabled by default")
+	fs.StringVar(&s.LockObjectNamespace, "lock-object-namespace", defaultLockObjectNamespace, "Define the namespace of the lock object; it is volcano-system by default")
+	fs.BoolVar(&s.PrintVersion, "version", false, "Show version and quit")
+	fs.StringVar(&s.ListenAddress, "listen-address", defaultListenAddress, "The address to listen on for HTTP requests.")
+	fs.StringVar(&s.HealthzBindAddress, "healthz-address", defaultHealthzAddress, "The address to listen on for the health check server.")
+	fs.BoolVar(&s.EnableMetrics, "enable-metrics", false, "Enable the metrics function; it is false by default")
+	fs.BoolVar(&s.EnablePprof, "enable-pprof", false, "Enable the pprof endpoint; it is false by default")
+	fs.BoolVar(&s.DisableHealthz, "disable-healthz", false, "Disable the health check; it is false by default")
+	fs.Int32Var(&s.MinPercentageOfNodesToFind, "minimum-percentage-nodes-to-find", defaultMinPercentageOfNodesToFind, "The minimum percentage of nodes that would be scored in each scheduling cycle, the default value is 5")
+	fs.Int32Var(&s.MinNodesToFind, "minimum-feasible-nodes", defaultMinNodesToFind, "The minimum number of feasible nodes that would be scored in each scheduling cycle, the default value is 100")
+	fs.Int32Var(&s.PercentageOfNodesToFind, "percentage-nodes-to-find", defaultPercentageOfNodesToFind, "The percentage of nodes that would be scored in each scheduling cycle, if <= 0, an adaptive percentage will be calculated")
+	fs.DurationVar(&s.QueueAllocateTimeout, "queue-allocate-timeout", defaultQueueAllocateTimeout, "The maximum wall-clock time the allocate action may spend on a single queue within one scheduling cycle, 0 means no limit")
+	fs.BoolVar(&s.EnableCacheDumper, "cache-dumper", true, "Enable the cache dumper; it is true by default")
+	fs.StringArrayVar(&s.NodeSelector, "node-selector", nil, "vc-scheduler will only schedule onto nodes whose labels match the node-selector")
+	fs.Uint32Var(&s.NodeWorkerThreads, "node-worker-threads", defaultNodeWorkers, "The number of threads syncing node operations.")
+}
+
+// CheckOptionOrDie checks the option and returns an error if it is invalid.
+func (s *ServerOption) CheckOptionOrDie() error {
+	if s.EnableLeaderElection && s.LockObjectNamespace == "" {
+		return fmt.Errorf("lock-object-namespace must not be nil when LeaderElection is enabled")
+	}
+	if s.QueueAllocateTimeout < 0 {
+		return fmt.Errorf("queue-allocate-timeout must not be negative")
+	}
+
+	return nil
+}
+
+// RegisterOptions registers options.
+func (s *ServerOption) RegisterOptions() {
+	ServerOpts = s
+}
+
+// Default new and registers a default set of options.
+func Default() *ServerOption {
+	s := NewServerOption()
+	s.RegisterOptions()
+	return s
+}
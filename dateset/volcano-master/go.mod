@@ -1,6 +1,8 @@
 module volcano.sh/volcano
 
-go 1.20
+go 1.21
+
+toolchain go1.21.6
 
 require (
 	github.com/agiledragon/gomonkey/v2 v2.2.0
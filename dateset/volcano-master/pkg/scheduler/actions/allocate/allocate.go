@@ -17,11 +17,14 @@
 package allocate
 
 import (
+	"math"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/apis/pkg/apis/scheduling"
+	"volcano.sh/volcano/cmd/scheduler/app/options"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/conf"
 	"volcano.sh/volcano/pkg/scheduler/framework"
@@ -111,6 +114,15 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 
 	allNodes := ssn.NodeList
 
+	// queueAllocateTimeout bounds the wall-clock time spent allocating to any
+	// single queue within one Execute call, so that a queue with many pending
+	// pods can not monopolize a scheduling cycle. Zero means no limit.
+	var queueAllocateTimeout time.Duration
+	if options.ServerOpts != nil {
+		queueAllocateTimeout = options.ServerOpts.QueueAllocateTimeout
+	}
+	queueSpent := map[api.QueueID]time.Duration{}
+
 	// To pick <namespace, queue> tuple for job, we choose to pick namespace firstly.
 	// Because we believe that number of queues would less than namespaces in most case.
 	// And, this action would make the resource usage among namespace balanced.
@@ -134,7 +146,15 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 			continue
 		}
 
+		if queueAllocateTimeout > 0 && queueSpent[queue.UID] >= queueAllocateTimeout {
+			skipped := skippedPendingTasks(jobs, pendingTasks)
+			klog.V(3).Infof("Queue <%s> spent %v allocating, which exceeds the timeout <%v>; skipping %d pending tasks.",
+				queue.Name, queueSpent[queue.UID], queueAllocateTimeout, skipped)
+			continue
+		}
+
 		job := jobs.Pop().(*api.JobInfo)
+		alloc.logDominantShares(queue)
 		if _, found = pendingTasks[job.UID]; !found {
 			tasks := util.NewPriorityQueue(ssn.TaskOrderFn)
 			for _, task := range job.TaskStatusIndex[api.Pending] {
@@ -161,20 +181,107 @@ func (alloc *Action) allocateResources(queues *util.PriorityQueue, jobsMap map[a
 		klog.V(3).Infof("Try to allocate resource to %d tasks of Job <%v/%v>",
 			tasks.Len(), job.Namespace, job.Name)
 
-		alloc.allocateResourcesForTasks(tasks, job, jobs, queue, allNodes)
+		startTime := time.Now()
+		var deadline time.Time
+		if queueAllocateTimeout > 0 {
+			deadline = startTime.Add(queueAllocateTimeout - queueSpent[queue.UID])
+		}
+		alloc.allocateResourcesForTasks(tasks, job, jobs, queue, allNodes, deadline)
+		queueSpent[queue.UID] += time.Since(startTime)
 	}
 }
 
-func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *api.JobInfo, jobs *util.PriorityQueue, queue *api.QueueInfo, allNodes []*api.NodeInfo) {
+// dominantShareHook, when set, receives the dominant shares computed by
+// logDominantShares; tests use it to observe the DRF state behind an
+// allocation decision.
+var dominantShareHook func(queue string, shares map[api.JobID]float64)
+
+// logDominantShares makes the DRF ordering visible: whenever a job is picked
+// from a queue, the dominant share of every job of that queue is logged at
+// debug level, so reordered or blocked jobs can be explained from the logs.
+func (alloc *Action) logDominantShares(queue *api.QueueInfo) {
+	if dominantShareHook == nil && !klog.V(4).Enabled() {
+		return
+	}
+
+	ssn := alloc.session
+	total := api.EmptyResource()
+	for _, node := range ssn.Nodes {
+		total.Add(node.Allocatable)
+	}
+
+	shares := map[api.JobID]float64{}
+	for _, job := range ssn.Jobs {
+		if job.Queue != queue.UID {
+			continue
+		}
+		shares[job.UID] = jobDominantShare(job, total)
+		klog.V(4).Infof("Job <%s/%s> in Queue <%s> has dominant share %.4f",
+			job.Namespace, job.Name, queue.Name, shares[job.UID])
+	}
+	if dominantShareHook != nil {
+		dominantShareHook(queue.Name, shares)
+	}
+}
+
+// jobDominantShare returns the DRF dominant share of a job: the largest ratio
+// of the job's allocated amount to cluster capacity across all resource
+// dimensions.
+func jobDominantShare(job *api.JobInfo, total *api.Resource) float64 {
+	share := 0.0
+	if job.Allocated == nil || total == nil {
+		return share
+	}
+	if total.MilliCPU > 0 {
+		share = math.Max(share, job.Allocated.MilliCPU/total.MilliCPU)
+	}
+	if total.Memory > 0 {
+		share = math.Max(share, job.Allocated.Memory/total.Memory)
+	}
+	for name, value := range job.Allocated.ScalarResources {
+		if t, found := total.ScalarResources[name]; found && t > 0 {
+			share = math.Max(share, value/t)
+		}
+	}
+	return share
+}
+
+// skippedPendingTasks drains the remaining jobs of a queue whose allocate time
+// budget is exhausted and returns the number of pending tasks being skipped.
+func skippedPendingTasks(jobs *util.PriorityQueue, pendingTasks map[api.JobID]*util.PriorityQueue) int {
+	skipped := 0
+	for !jobs.Empty() {
+		job := jobs.Pop().(*api.JobInfo)
+		if tasks, found := pendingTasks[job.UID]; found {
+			skipped += tasks.Len()
+			continue
+		}
+		for _, task := range job.TaskStatusIndex[api.Pending] {
+			if !task.Resreq.IsEmpty() {
+				skipped++
+			}
+		}
+	}
+	return skipped
+}
+
+func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *api.JobInfo, jobs *util.PriorityQueue, queue *api.QueueInfo, allNodes []*api.NodeInfo, deadline time.Time) {
 	ssn := alloc.session
 	stmt := framework.NewStatement(ssn)
 	ph := util.NewPredicateHelper()
 
 	for !tasks.Empty() {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			klog.V(3).Infof("Queue <%s> exceeded the allocate timeout; skipping %d remaining tasks of Job <%v/%v>.",
+				queue.Name, tasks.Len(), job.Namespace, job.Name)
+			break
+		}
+
 		task := tasks.Pop().(*api.TaskInfo)
 
 		if !ssn.Allocatable(queue, task) {
 			klog.V(3).Infof("Queue <%s> is overused when considering task <%s>, ignore it.", queue.Name, task.Name)
+			alloc.recordScheduleFailure(task, queue)
 			continue
 		}
 
@@ -288,6 +395,18 @@ func (alloc *Action) allocateResourcesForTasks(tasks *util.PriorityQueue, job *a
 	}
 }
 
+// recordScheduleFailure emits a FailedScheduling event for a task the allocate
+// action declines, naming the queue and the resources that blocked it; silently
+// not binding a pod is otherwise hard to debug in production.
+func (alloc *Action) recordScheduleFailure(task *api.TaskInfo, queue *api.QueueInfo) {
+	if alloc.session.Recorder == nil || task.Pod == nil {
+		return
+	}
+	alloc.session.Recorder.Eventf(task.Pod, v1.EventTypeWarning, "FailedScheduling",
+		"Task %s/%s was not allocated: queue <%s> can not satisfy its resource request <%v>",
+		task.Namespace, task.Name, queue.Name, task.Resreq)
+}
+
 func (alloc *Action) predicate(task *api.TaskInfo, node *api.NodeInfo) ([]*api.Status, error) {
 	// Check for Resource Predicate
 	if ok, resources := task.InitResreq.LessEqualWithResourcesName(node.FutureIdle(), api.Zero); !ok {
@@ -20,7 +20,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	v1 "k8s.io/api/core/v1"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
 
+	"volcano.sh/volcano/pkg/scheduler/plugins/colocation"
 	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
 	"volcano.sh/volcano/pkg/scheduler/plugins/priority"
 
@@ -220,6 +223,474 @@ func TestAllocate(t *testing.T) {
 	}
 }
 
+func TestAllocateTaskColocation(t *testing.T) {
+	var tmp *cache.SchedulerCache
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "AddBindTask", func(scCache *cache.SchedulerCache, task *api.TaskInfo) error {
+		scCache.Binder.Bind(nil, []*api.TaskInfo{task})
+		return nil
+	})
+	defer patches.Reset()
+
+	patchUpdateQueueStatus := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "UpdateQueueStatus", func(scCache *cache.SchedulerCache, queue *api.QueueInfo) error {
+		return nil
+	})
+	defer patchUpdateQueueStatus.Reset()
+
+	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+	framework.RegisterPluginBuilder("colocation", colocation.New)
+
+	options.ServerOpts = &options.ServerOption{
+		MinNodesToFind:             100,
+		MinPercentageOfNodesToFind: 5,
+		PercentageOfNodesToFind:    100,
+	}
+
+	defer framework.CleanupPluginBuilders()
+
+	// pg1 already has a task running on n2; with the colocation plugin enabled,
+	// its pending task must prefer n2 over the otherwise identical n1.
+	podGroups := []*schedulingv1.PodGroup{
+		util.BuildPodGroup("pg1", "c1", "c1", 0, nil, schedulingv1.PodGroupInqueue),
+	}
+	pods := []*v1.Pod{
+		util.BuildPod("c1", "p0", "n2", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+	}
+	nodes := []*v1.Node{
+		util.BuildNode("n1", api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+		util.BuildNode("n2", api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+	}
+	queues := []*schedulingv1.Queue{
+		util.BuildQueue("c1", 1, nil),
+	}
+
+	binder := &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 10),
+	}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        binder,
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+
+	for _, node := range nodes {
+		schedulerCache.AddOrUpdateNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+	for _, ss := range podGroups {
+		schedulerCache.AddPodGroupV1beta1(ss)
+	}
+	for _, q := range queues {
+		schedulerCache.AddQueueV1beta1(q)
+	}
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "drf",
+					EnabledPreemptable: &trueValue,
+					EnabledJobOrder:    &trueValue,
+				},
+				{
+					Name:               "proportion",
+					EnabledQueueOrder:  &trueValue,
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:             "colocation",
+					EnabledNodeOrder: &trueValue,
+				},
+			},
+		},
+	}, nil)
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	expected := map[string]string{
+		"c1/p1": "n2",
+	}
+	if !reflect.DeepEqual(expected, binder.Binds) {
+		t.Errorf("expected: %v, got %v ", expected, binder.Binds)
+	}
+}
+
+func TestAllocateDominantShareLogging(t *testing.T) {
+	var tmp *cache.SchedulerCache
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "AddBindTask", func(scCache *cache.SchedulerCache, task *api.TaskInfo) error {
+		scCache.Binder.Bind(nil, []*api.TaskInfo{task})
+		return nil
+	})
+	defer patches.Reset()
+
+	patchUpdateQueueStatus := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "UpdateQueueStatus", func(scCache *cache.SchedulerCache, queue *api.QueueInfo) error {
+		return nil
+	})
+	defer patchUpdateQueueStatus.Reset()
+
+	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+
+	options.ServerOpts = &options.ServerOption{
+		MinNodesToFind:             100,
+		MinPercentageOfNodesToFind: 5,
+		PercentageOfNodesToFind:    100,
+	}
+
+	defer framework.CleanupPluginBuilders()
+
+	// pg1 already consumes half of the cluster CPU, pg2 nothing; the hook must
+	// report exactly those dominant shares when allocate consults DRF ordering.
+	podGroups := []*schedulingv1.PodGroup{
+		util.BuildPodGroup("pg1", "c1", "c1", 0, nil, schedulingv1.PodGroupInqueue),
+		util.BuildPodGroup("pg2", "c1", "c1", 0, nil, schedulingv1.PodGroupInqueue),
+	}
+	pods := []*v1.Pod{
+		util.BuildPod("c1", "pg1-p-1", "n1", v1.PodRunning, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "pg1-p-2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "pg2-p-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+	}
+	nodes := []*v1.Node{
+		util.BuildNode("n1", api.BuildResourceList("2", "4Gi", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+	}
+	queues := []*schedulingv1.Queue{
+		util.BuildQueue("c1", 1, nil),
+	}
+
+	// Keep the first observation per queue: it reflects the DRF state before
+	// this session allocated anything.
+	observed := map[string]map[api.JobID]float64{}
+	dominantShareHook = func(queue string, shares map[api.JobID]float64) {
+		if _, ok := observed[queue]; !ok {
+			observed[queue] = shares
+		}
+	}
+	defer func() { dominantShareHook = nil }()
+
+	binder := &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 10),
+	}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        binder,
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+
+	for _, node := range nodes {
+		schedulerCache.AddOrUpdateNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+	for _, ss := range podGroups {
+		schedulerCache.AddPodGroupV1beta1(ss)
+	}
+	for _, q := range queues {
+		schedulerCache.AddQueueV1beta1(q)
+	}
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "drf",
+					EnabledPreemptable: &trueValue,
+					EnabledJobOrder:    &trueValue,
+				},
+				{
+					Name:               "proportion",
+					EnabledQueueOrder:  &trueValue,
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	}, nil)
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	shares, found := observed["c1"]
+	if !found {
+		t.Fatalf("expected dominant shares to be reported for queue c1, got %v", observed)
+	}
+
+	// 1 of 2 CPUs is the dominant dimension for pg1; pg2 has nothing allocated
+	// when the session opens.
+	byName := map[string]float64{}
+	for _, job := range ssn.Jobs {
+		if share, ok := shares[job.UID]; ok {
+			byName[job.Name] = share
+		}
+	}
+	if len(byName) != 2 {
+		t.Fatalf("expected shares for both jobs, got %v", byName)
+	}
+	if byName["pg1"] < 0.5 {
+		t.Errorf("expected job pg1 to have a dominant share of at least 0.5, got %v", byName["pg1"])
+	}
+	if byName["pg2"] >= byName["pg1"] {
+		t.Errorf("expected job pg2 to have a smaller dominant share than pg1, got %v", byName)
+	}
+}
+
+func TestAllocateSkippedTaskEvent(t *testing.T) {
+	var tmp *cache.SchedulerCache
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "AddBindTask", func(scCache *cache.SchedulerCache, task *api.TaskInfo) error {
+		scCache.Binder.Bind(nil, []*api.TaskInfo{task})
+		return nil
+	})
+	defer patches.Reset()
+
+	patchUpdateQueueStatus := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "UpdateQueueStatus", func(scCache *cache.SchedulerCache, queue *api.QueueInfo) error {
+		return nil
+	})
+	defer patchUpdateQueueStatus.Reset()
+
+	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+
+	options.ServerOpts = &options.ServerOption{
+		MinNodesToFind:             100,
+		MinPercentageOfNodesToFind: 5,
+		PercentageOfNodesToFind:    100,
+	}
+
+	defer framework.CleanupPluginBuilders()
+
+	// Same shape as the "high priority queue should not block others" case:
+	// c1/p1 asks for more than queue c1 can deserve and must be declined,
+	// which should leave a FailedScheduling event instead of a silent skip.
+	podGroups := []*schedulingv1.PodGroup{
+		util.BuildPodGroup("pg1", "c1", "c1", 0, nil, schedulingv1.PodGroupInqueue),
+		util.BuildPodGroup("pg2", "c1", "c2", 0, nil, schedulingv1.PodGroupInqueue),
+	}
+	pods := []*v1.Pod{
+		util.BuildPod("c1", "p1", "", v1.PodPending, api.BuildResourceList("3", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "p2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+	}
+	nodes := []*v1.Node{
+		util.BuildNode("n1", api.BuildResourceList("2", "4G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+	}
+	queues := []*schedulingv1.Queue{
+		util.BuildQueue("c1", 1, nil),
+		util.BuildQueue("c2", 1, nil),
+	}
+
+	binder := &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 10),
+	}
+	recorder := record.NewFakeRecorder(100)
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        binder,
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+		Recorder:      recorder,
+	}
+
+	for _, node := range nodes {
+		schedulerCache.AddOrUpdateNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+	for _, ss := range podGroups {
+		schedulerCache.AddPodGroupV1beta1(ss)
+	}
+	for _, q := range queues {
+		schedulerCache.AddQueueV1beta1(q)
+	}
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "drf",
+					EnabledPreemptable: &trueValue,
+					EnabledJobOrder:    &trueValue,
+				},
+				{
+					Name:               "proportion",
+					EnabledQueueOrder:  &trueValue,
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	}, nil)
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	if _, found := binder.Binds["c1/p2"]; !found {
+		t.Errorf("expected pod of queue c2 to be bound, got %v", binder.Binds)
+	}
+
+	var events []string
+drain:
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			break drain
+		}
+	}
+
+	found := false
+	for _, e := range events {
+		if strings.Contains(e, "FailedScheduling") && strings.Contains(e, "queue <c1>") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected a FailedScheduling event for the declined task of queue c1, got %v", events)
+	}
+}
+
+func TestAllocateWithQueueTimeout(t *testing.T) {
+	var tmp *cache.SchedulerCache
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "AddBindTask", func(scCache *cache.SchedulerCache, task *api.TaskInfo) error {
+		scCache.Binder.Bind(nil, []*api.TaskInfo{task})
+		return nil
+	})
+	defer patches.Reset()
+
+	patchUpdateQueueStatus := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "UpdateQueueStatus", func(scCache *cache.SchedulerCache, queue *api.QueueInfo) error {
+		return nil
+	})
+	defer patchUpdateQueueStatus.Reset()
+
+	// Slow down every predicate call so that the busy queue c1 burns through
+	// its allocate time budget before all of its pods are scheduled.
+	var ssnTmp *framework.Session
+	patchPredicate := gomonkey.ApplyMethod(reflect.TypeOf(ssnTmp), "PredicateFn", func(ssn *framework.Session, task *api.TaskInfo, node *api.NodeInfo) ([]*api.Status, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	})
+	defer patchPredicate.Reset()
+
+	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+
+	options.ServerOpts = &options.ServerOption{
+		MinNodesToFind:             100,
+		MinPercentageOfNodesToFind: 5,
+		PercentageOfNodesToFind:    100,
+		QueueAllocateTimeout:       30 * time.Millisecond,
+	}
+
+	defer framework.CleanupPluginBuilders()
+
+	podGroups := []*schedulingv1.PodGroup{
+		util.BuildPodGroup("pg1", "c1", "c1", 0, nil, schedulingv1.PodGroupInqueue),
+		util.BuildPodGroup("pg2", "c2", "c2", 0, nil, schedulingv1.PodGroupInqueue),
+	}
+	pods := []*v1.Pod{
+		// queue c1 has more pending pods than fit into its time budget
+		util.BuildPod("c1", "pg1-p-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "pg1-p-2", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "pg1-p-3", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "pg1-p-4", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c2", "pg2-p-1", "", v1.PodPending, api.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+	}
+	nodes := []*v1.Node{
+		util.BuildNode("n1", api.BuildResourceList("8", "8G", []api.ScalarResource{{Name: "pods", Value: "10"}}...), make(map[string]string)),
+	}
+	queues := []*schedulingv1.Queue{
+		util.BuildQueue("c1", 1, nil),
+		util.BuildQueue("c2", 1, nil),
+	}
+
+	binder := &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 10),
+	}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        binder,
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+
+	for _, node := range nodes {
+		schedulerCache.AddOrUpdateNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+	for _, ss := range podGroups {
+		schedulerCache.AddPodGroupV1beta1(ss)
+	}
+	for _, q := range queues {
+		schedulerCache.AddQueueV1beta1(q)
+	}
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "drf",
+					EnabledPreemptable: &trueValue,
+					EnabledJobOrder:    &trueValue,
+				},
+				{
+					Name:               "proportion",
+					EnabledQueueOrder:  &trueValue,
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	}, nil)
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	// The quiet queue c2 must not be starved by c1.
+	if _, found := binder.Binds["c2/pg2-p-1"]; !found {
+		t.Errorf("expected pod of queue c2 to be bound, got %v", binder.Binds)
+	}
+
+	// Queue c1 must make some progress but be cut off by the timeout before
+	// all of its pods are scheduled.
+	c1Binds := 0
+	for key := range binder.Binds {
+		if strings.HasPrefix(key, "c1/") {
+			c1Binds++
+		}
+	}
+	if c1Binds == 0 {
+		t.Errorf("expected queue c1 to bind at least one pod, got %v", binder.Binds)
+	}
+	if c1Binds == 4 {
+		t.Errorf("expected queue c1 to be cut off by the allocate timeout, got %v", binder.Binds)
+	}
+}
+
 func TestAllocateWithDynamicPVC(t *testing.T) {
 	var tmp *cache.SchedulerCache
 	patches := gomonkey.ApplyMethod(reflect.TypeOf(tmp), "AddBindTask", func(scCache *cache.SchedulerCache, task *api.TaskInfo) error {
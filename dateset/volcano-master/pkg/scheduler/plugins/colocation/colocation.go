@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package colocation
+
+import (
+	"k8s.io/klog/v2"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName indicates name of volcano scheduler plugin.
+	PluginName = "colocation"
+
+	// ColocationEnable is the key for enabling job task co-location scoring in scheduler configmap
+	ColocationEnable = "colocation.Enable"
+
+	// ColocationWeight is the key for the weight of the co-location score in scheduler configmap
+	ColocationWeight = "colocation.Weight"
+)
+
+type colocationPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+}
+
+// New return colocation plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	return &colocationPlugin{pluginArguments: arguments}
+}
+
+func (cp *colocationPlugin) Name() string {
+	return PluginName
+}
+
+type colocationEnable struct {
+	colocationEnable bool
+	colocationWeight int
+}
+
+func enableColocation(args framework.Arguments) colocationEnable {
+	/*
+	   User should give colocation configuration in this format:
+
+	   actions: "reclaim, allocate, backfill, preempt"
+	   tiers:
+	   - plugins:
+	     - name: priority
+	     - name: gang
+	   - plugins:
+	     - name: drf
+	     - name: colocation
+	       arguments:
+	         colocation.Enable: true
+	         colocation.Weight: 1
+	     - name: proportion
+	*/
+
+	colocation := colocationEnable{
+		colocationEnable: true,
+		colocationWeight: 1,
+	}
+
+	// Checks whether colocation args were given by scheduler configmap; if so,
+	// cover the values in colocationEnable struct.
+	args.GetBool(&colocation.colocationEnable, ColocationEnable)
+	args.GetInt(&colocation.colocationWeight, ColocationWeight)
+	if colocation.colocationWeight < 0 {
+		colocation.colocationWeight = 1
+	}
+
+	return colocation
+}
+
+func (cp *colocationPlugin) OnSessionOpen(ssn *framework.Session) {
+	colocation := enableColocation(cp.pluginArguments)
+	if !colocation.colocationEnable {
+		klog.V(4).Infof("colocation plugin is disabled by %s", ColocationEnable)
+		return
+	}
+
+	// Score candidate nodes higher when they already host tasks of the same
+	// job, so a job's later tasks prefer the nodes its earlier tasks landed on
+	// and cross-node traffic within the job is reduced.
+	ssn.AddNodeOrderFn(cp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		count := 0
+		for _, t := range node.Tasks {
+			if t.Job == task.Job {
+				count++
+			}
+		}
+		score := float64(colocation.colocationWeight * count)
+		klog.V(4).Infof("Task <%s/%s> on node <%s> got colocation score %v with <%d> tasks of Job <%s> on the node",
+			task.Namespace, task.Name, node.Name, score, count, task.Job)
+		return score, nil
+	})
+}
+
+func (cp *colocationPlugin) OnSessionClose(ssn *framework.Session) {}